@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/smith-30/go-monkey/ast"
+	"github.com/smith-30/go-monkey/lexer"
+	"github.com/smith-30/go-monkey/parser"
+)
+
+// runAST implements `monkey ast --json|--dot [file]`, which prints the
+// syntax tree of the file, or of the standard input, as JSON or as a
+// Graphviz graph and returns the exit status.
+func runAST(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("ast", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "print the tree as JSON")
+	asDot := flags.Bool("dot", false, "print the tree as a Graphviz graph in the DOT language")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: monkey ast --json|--dot [file]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *asJSON == *asDot || flags.NArg() > 1 {
+		flags.Usage()
+		return 2
+	}
+
+	var src []byte
+	var err error
+	if flags.NArg() == 0 {
+		src, err = ioutil.ReadAll(stdin)
+	} else {
+		src, err = ioutil.ReadFile(flags.Arg(0))
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "monkey ast: %s\n", err)
+		return 1
+	}
+
+	p := parser.New(lexer.New(string(src)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		fmt.Fprintf(stderr, "monkey ast: parse error: %s\n", strings.Join(p.Errors(), "; "))
+		return 1
+	}
+
+	if *asDot {
+		_, err = io.WriteString(stdout, ast.ToDot(program))
+	} else {
+		err = writeJSON(stdout, program)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "monkey ast: %s\n", err)
+		return 1
+	}
+	return 0
+}
+
+func writeJSON(w io.Writer, program *ast.Program) error {
+	data, err := ast.MarshalJSON(program)
+	if err == nil {
+		var out bytes.Buffer
+		if err = json.Indent(&out, data, "", "  "); err == nil {
+			out.WriteByte('\n')
+			_, err = out.WriteTo(w)
+		}
+	}
+	return err
+}
@@ -11,6 +11,7 @@ type (
 	Node interface {
 		TokenLiteral() string
 		String() string
+		Pos() token.Position
 	}
 
 	Statement interface {
@@ -38,6 +39,10 @@ func (ls *LetStatement) TokenLiteral() string {
 	return ls.Token.Literal
 }
 
+func (ls *LetStatement) Pos() token.Position {
+	return ls.Token.Pos
+}
+
 func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 
@@ -67,6 +72,10 @@ func (rs *ReturnStatement) TokenLiteral() string {
 	return rs.Token.Literal
 }
 
+func (rs *ReturnStatement) Pos() token.Position {
+	return rs.Token.Pos
+}
+
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
 
@@ -81,6 +90,74 @@ func (rs *ReturnStatement) String() string {
 	return out.String()
 }
 
+type (
+	// throw <expression>;
+	ThrowStatement struct {
+		Token token.Token // expects throw
+		Value Expression
+	}
+)
+
+func (ts *ThrowStatement) statementNode() {}
+
+func (ts *ThrowStatement) TokenLiteral() string {
+	return ts.Token.Literal
+}
+
+func (ts *ThrowStatement) Pos() token.Position {
+	return ts.Token.Pos
+}
+
+func (ts *ThrowStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ts.TokenLiteral() + " ")
+
+	if ts.Value != nil {
+		out.WriteString(ts.Value.String())
+	}
+
+	out.WriteString(";")
+
+	return out.String()
+}
+
+type (
+	YieldStatement struct {
+		Token    token.Token // expects yield
+		Value    Expression
+		Delegate bool // yield* yields every value of an iterator or array
+	}
+)
+
+func (ys *YieldStatement) statementNode() {}
+
+func (ys *YieldStatement) TokenLiteral() string {
+	return ys.Token.Literal
+}
+
+func (ys *YieldStatement) Pos() token.Position {
+	return ys.Token.Pos
+}
+
+func (ys *YieldStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ys.TokenLiteral())
+	if ys.Delegate {
+		out.WriteString("*")
+	}
+	out.WriteString(" ")
+
+	if ys.Value != nil {
+		out.WriteString(ys.Value.String())
+	}
+
+	out.WriteString(";")
+
+	return out.String()
+}
+
 type (
 	Identifier struct {
 		Token token.Token // expects token.IDENT
@@ -94,6 +171,10 @@ func (i *Identifier) TokenLiteral() string {
 	return i.Token.Literal
 }
 
+func (i *Identifier) Pos() token.Position {
+	return i.Token.Pos
+}
+
 func (i *Identifier) String() string {
 	return i.Value
 }
@@ -101,6 +182,7 @@ func (i *Identifier) String() string {
 type ArrayLiteral struct {
 	Token    token.Token
 	Elements []Expression
+	Rbracket token.Position // position of the closing ]
 }
 
 func (a *ArrayLiteral) expressionNode() {}
@@ -109,6 +191,10 @@ func (a *ArrayLiteral) TokenLiteral() string {
 	return a.Token.Literal
 }
 
+func (a *ArrayLiteral) Pos() token.Position {
+	return a.Token.Pos
+}
+
 func (a *ArrayLiteral) String() string {
 	var out bytes.Buffer
 
@@ -137,6 +223,10 @@ func (es *ExpressionStatement) TokenLiteral() string {
 	return es.Token.Literal
 }
 
+func (es *ExpressionStatement) Pos() token.Position {
+	return es.Token.Pos
+}
+
 func (es *ExpressionStatement) String() string {
 	if es.Expression != nil {
 		return es.Expression.String()
@@ -158,6 +248,10 @@ func (pe *PrefixExpression) TokenLiteral() string {
 	return pe.Token.Literal
 }
 
+func (pe *PrefixExpression) Pos() token.Position {
+	return pe.Token.Pos
+}
+
 func (pe *PrefixExpression) String() string {
 	var out bytes.Buffer
 
@@ -184,6 +278,10 @@ func (ie *InfixExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
 
+func (ie *InfixExpression) Pos() token.Position {
+	return ie.Token.Pos
+}
+
 func (ie *InfixExpression) String() string {
 	var out bytes.Buffer
 
@@ -212,28 +310,81 @@ func (ie *IfExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
 
+func (ie *IfExpression) Pos() token.Position {
+	return ie.Token.Pos
+}
+
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
 
-	out.WriteString("if")
-	out.WriteString(ie.Condition.String())
+	out.WriteString("if ")
+	// infix, prefix and index expressions print their own parentheses
+	switch ie.Condition.(type) {
+	case *InfixExpression, *PrefixExpression, *IndexExpression:
+		out.WriteString(ie.Condition.String())
+	default:
+		out.WriteString("(" + ie.Condition.String() + ")")
+	}
 	out.WriteString(" ")
 	out.WriteString(ie.Consequence.String())
 
 	if ie.Alternative != nil {
-		out.WriteString("else ")
+		out.WriteString(" else ")
 		out.WriteString(ie.Alternative.String())
 	}
 
 	return out.String()
 }
 
+type (
+	// try <block> catch (<identifier>) <block> finally <block>
+	// either catch or finally may be omitted, but not both
+	TryExpression struct {
+		Token   token.Token // expects try
+		Block   *BlockStatement
+		Param   *Identifier // bound to the caught error
+		Catch   *BlockStatement
+		Finally *BlockStatement
+	}
+)
+
+func (te *TryExpression) expressionNode() {}
+
+func (te *TryExpression) TokenLiteral() string {
+	return te.Token.Literal
+}
+
+func (te *TryExpression) Pos() token.Position {
+	return te.Token.Pos
+}
+
+func (te *TryExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("try ")
+	out.WriteString(te.Block.String())
+
+	if te.Catch != nil {
+		out.WriteString(" catch (" + te.Param.String() + ") ")
+		out.WriteString(te.Catch.String())
+	}
+
+	if te.Finally != nil {
+		out.WriteString(" finally ")
+		out.WriteString(te.Finally.String())
+	}
+
+	return out.String()
+}
+
 type (
 	// fn <parameters> <block statement>
 	FunctionLiteral struct {
 		Token      token.Token
 		Parameters []*Identifier
 		Body       *BlockStatement
+		Name       string // name of the let binding, empty for anonymous functions
+		Generator  bool   // the body yields, so calls return an iterator
 	}
 )
 
@@ -243,6 +394,10 @@ func (fl *FunctionLiteral) TokenLiteral() string {
 	return fl.Token.Literal
 }
 
+func (fl *FunctionLiteral) Pos() token.Position {
+	return fl.Token.Pos
+}
+
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 
@@ -260,12 +415,49 @@ func (fl *FunctionLiteral) String() string {
 	return out.String()
 }
 
+type (
+	// macro <parameters> <block statement>
+	MacroLiteral struct {
+		Token      token.Token
+		Parameters []*Identifier
+		Body       *BlockStatement
+	}
+)
+
+func (ml *MacroLiteral) expressionNode() {}
+
+func (ml *MacroLiteral) TokenLiteral() string {
+	return ml.Token.Literal
+}
+
+func (ml *MacroLiteral) Pos() token.Position {
+	return ml.Token.Pos
+}
+
+func (ml *MacroLiteral) String() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range ml.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString(ml.TokenLiteral())
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") ")
+	out.WriteString(ml.Body.String())
+
+	return out.String()
+}
+
 type (
 	// <expression>(<comma separated expressions>)
 	CallExpression struct {
 		Token     token.Token // '('
 		Function  Expression
 		Arguments []Expression
+		Rparen    token.Position // position of the closing )
 	}
 )
 
@@ -275,6 +467,10 @@ func (ce *CallExpression) TokenLiteral() string {
 	return ce.Token.Literal
 }
 
+func (ce *CallExpression) Pos() token.Position {
+	return ce.Token.Pos
+}
+
 func (ce *CallExpression) String() string {
 	var out bytes.Buffer
 
@@ -295,6 +491,7 @@ type (
 	BlockStatement struct {
 		Token      token.Token
 		Statements []Statement
+		Rbrace     token.Position // position of the closing }
 	}
 )
 
@@ -304,16 +501,39 @@ func (bs *BlockStatement) TokenLiteral() string {
 	return bs.Token.Literal
 }
 
+func (bs *BlockStatement) Pos() token.Position {
+	return bs.Token.Pos
+}
+
 func (bs *BlockStatement) String() string {
+	if len(bs.Statements) == 0 {
+		return "{}"
+	}
+
 	var out bytes.Buffer
 
-	for _, s := range bs.Statements {
-		out.WriteString(s.String())
-	}
+	out.WriteString("{ ")
+	writeStatements(&out, bs.Statements)
+	out.WriteString(" }")
 
 	return out.String()
 }
 
+// writeStatements writes list separated by spaces. Expression statements
+// followed by another statement get a semicolon, so that the next one
+// can not be read as a part of them, like a call in `f (x)`.
+func writeStatements(out *bytes.Buffer, list []Statement) {
+	for i, s := range list {
+		if i > 0 {
+			out.WriteString(" ")
+		}
+		out.WriteString(s.String())
+		if _, ok := s.(*ExpressionStatement); ok && i+1 < len(list) {
+			out.WriteString(";")
+		}
+	}
+}
+
 type (
 	// <expression>[<expression>] ex.) array[0]
 	IndexExpression struct {
@@ -329,6 +549,10 @@ func (ie *IndexExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
 
+func (ie *IndexExpression) Pos() token.Position {
+	return ie.Token.Pos
+}
+
 func (ie *IndexExpression) String() string {
 	var out bytes.Buffer
 
@@ -345,8 +569,15 @@ func (ie *IndexExpression) String() string {
 type (
 	// {<expression> : <expression>, <expression>: <expression>, ...}
 	HashLiteral struct {
-		Token token.Token // '{' token
-		Pairs map[Expression]Expression
+		Token  token.Token    // '{' token
+		Pairs  []HashPair     // in source order
+		Rbrace token.Position // position of the closing }
+	}
+
+	// HashPair is a key and its value in a hash literal.
+	HashPair struct {
+		Key   Expression
+		Value Expression
 	}
 )
 
@@ -356,12 +587,16 @@ func (hl *HashLiteral) TokenLiteral() string {
 	return hl.Token.Literal
 }
 
+func (hl *HashLiteral) Pos() token.Position {
+	return hl.Token.Pos
+}
+
 func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
 
 	pairs := []string{}
-	for k, v := range hl.Pairs {
-		pairs = append(pairs, k.String()+":"+v.String())
+	for _, pair := range hl.Pairs {
+		pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
 	}
 	out.WriteString("{")
 	out.WriteString(strings.Join(pairs, ", "))
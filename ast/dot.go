@@ -0,0 +1,88 @@
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// ToDot renders the tree rooted at node as a Graphviz graph in the DOT
+// language. Each node is labeled with its kind, its operator or literal
+// value if it has one, and its position. Children are ordered as in the
+// source, so the left operand of an operator is drawn on the left.
+func ToDot(node Node) string {
+	v := &dotVisitor{}
+	v.out.WriteString("digraph AST {\n")
+	v.out.WriteString("\tordering=out;\n")
+	v.out.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+	Walk(v, node)
+	v.out.WriteString("}\n")
+	return v.out.String()
+}
+
+type dotVisitor struct {
+	out     bytes.Buffer
+	next    int
+	parents []int // ids of the nodes being visited, innermost last
+}
+
+func (v *dotVisitor) Visit(node Node) Visitor {
+	if node == nil {
+		v.parents = v.parents[:len(v.parents)-1]
+		return nil
+	}
+
+	id := v.next
+	v.next++
+
+	fmt.Fprintf(&v.out, "\tn%d [label=%s];\n", id, dotQuote(dotLabel(node)))
+	if len(v.parents) > 0 {
+		fmt.Fprintf(&v.out, "\tn%d -> n%d;\n", v.parents[len(v.parents)-1], id)
+	}
+
+	v.parents = append(v.parents, id)
+	return v
+}
+
+// dotLabel describes node by its kind, then its details and position on
+// lines of their own.
+func dotLabel(node Node) string {
+	lines := []string{kindOf(node)}
+
+	switch n := node.(type) {
+	case *Identifier:
+		lines = append(lines, n.Value)
+	case *IntegerLiteral:
+		lines = append(lines, n.Token.Literal)
+	case *StringLiteral:
+		lines = append(lines, fmt.Sprintf("%q", n.Value))
+	case *Boolean:
+		lines = append(lines, fmt.Sprint(n.Value))
+	case *PrefixExpression:
+		lines = append(lines, n.Operator)
+	case *InfixExpression:
+		lines = append(lines, n.Operator)
+	case *YieldStatement:
+		if n.Delegate {
+			lines = append(lines, "yield*")
+		}
+	case *FunctionLiteral:
+		if n.Name != "" {
+			lines = append(lines, n.Name)
+		}
+		if n.Generator {
+			lines = append(lines, "generator")
+		}
+	}
+
+	if pos := node.Pos(); pos.IsValid() {
+		lines = append(lines, pos.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// dotQuote returns s as a DOT string.
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,55 @@
+package ast_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/smith-30/go-monkey/ast"
+)
+
+func TestToDot(t *testing.T) {
+	program := parse(t, `let s = -a + "q";`)
+
+	exp := `digraph AST {
+	ordering=out;
+	node [shape=box, fontname="monospace"];
+	n0 [label="Program\n1:1"];
+	n1 [label="LetStatement\n1:1"];
+	n0 -> n1;
+	n2 [label="Identifier\ns\n1:5"];
+	n1 -> n2;
+	n3 [label="InfixExpression\n+\n1:12"];
+	n1 -> n3;
+	n4 [label="PrefixExpression\n-\n1:9"];
+	n3 -> n4;
+	n5 [label="Identifier\na\n1:10"];
+	n4 -> n5;
+	n6 [label="StringLiteral\n\"q\"\n1:14"];
+	n3 -> n6;
+}
+`
+	if got := ast.ToDot(program); got != exp {
+		t.Errorf("want\n%s\nbut\n%s", exp, got)
+	}
+}
+
+func TestToDotLabels(t *testing.T) {
+	tests := []struct {
+		input string
+		exp   string
+	}{
+		{`let add = fn() { yield* [true]; }`, `"FunctionLiteral\nadd\ngenerator\n1:11"`},
+		{`fn() { yield* [true]; }`, `"YieldStatement\nyield*\n1:8"`},
+		{`[true]`, `"Boolean\ntrue\n1:2"`},
+		{`010`, `"IntegerLiteral\n010\n1:1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			dot := ast.ToDot(parse(t, tt.input))
+			if !strings.Contains(dot, "[label="+tt.exp+"]") {
+				t.Errorf("label %s not found in\n%s", tt.exp, dot)
+			}
+		})
+	}
+}
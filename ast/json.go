@@ -0,0 +1,593 @@
+package ast
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/smith-30/go-monkey/token"
+)
+
+// JSON encoding of the AST.
+//
+// Every node is an object with a "kind", the name of its Go type like
+// "LetStatement", and a "pos" holding its "line" and "column". The other
+// members are the fields of the node type in lower camel case. Child nodes
+// are nested objects and lists of nodes are arrays; missing children and
+// unknown positions are left out. The closing delimiters of blocks, calls,
+// arrays and hashes are in "rbrace", "rparen" and "rbracket", and the pairs
+// of a hash are an array of objects with a "key" and a "value".
+//
+// Tokens are not encoded, they are rebuilt from the kind of the node.
+
+// MarshalJSON returns the JSON encoding of node.
+func MarshalJSON(node Node) ([]byte, error) {
+	return json.Marshal(encodeNode(node))
+}
+
+// UnmarshalNode decodes a node encoded by MarshalJSON.
+func UnmarshalNode(data []byte) (Node, error) {
+	return decodeNode(data)
+}
+
+// UnmarshalProgram decodes a program encoded by MarshalJSON.
+func UnmarshalProgram(data []byte) (*Program, error) {
+	node, err := decodeNode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	program, ok := node.(*Program)
+	if !ok {
+		return nil, fmt.Errorf("ast: want Program, got %s", kindOf(node))
+	}
+	return program, nil
+}
+
+type jsonPos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type jsonObject map[string]interface{}
+
+func kindOf(node Node) string {
+	return fmt.Sprintf("%T", node)[len("*ast."):]
+}
+
+func encodeNode(node Node) jsonObject {
+	obj := jsonObject{"kind": kindOf(node)}
+	obj.setPos("pos", node.Pos())
+
+	switch n := node.(type) {
+	case *Program:
+		obj["statements"] = encodeStatements(n.Statements)
+		if len(n.Comments) > 0 {
+			comments := make([]jsonObject, len(n.Comments))
+			for i, c := range n.Comments {
+				comments[i] = encodeNode(c)
+			}
+			obj["comments"] = comments
+		}
+	case *Comment:
+		obj["text"] = n.Token.Literal
+		obj["trailing"] = n.Trailing
+
+	// statements
+	case *LetStatement:
+		obj.set("name", n.Name)
+		obj.set("value", n.Value)
+	case *ReturnStatement:
+		obj.set("returnValue", n.ReturnValue)
+	case *ThrowStatement:
+		obj.set("value", n.Value)
+	case *YieldStatement:
+		obj.set("value", n.Value)
+		obj["delegate"] = n.Delegate
+	case *ExpressionStatement:
+		obj.set("expression", n.Expression)
+	case *BlockStatement:
+		obj["statements"] = encodeStatements(n.Statements)
+		obj.setPos("rbrace", n.Rbrace)
+
+	// expressions
+	case *Identifier:
+		obj["value"] = n.Value
+	case *IntegerLiteral:
+		obj["value"] = n.Value
+		obj["literal"] = n.Token.Literal
+	case *StringLiteral:
+		obj["value"] = n.Value
+	case *Boolean:
+		obj["value"] = n.Value
+	case *PrefixExpression:
+		obj["operator"] = n.Operator
+		obj.set("right", n.Right)
+	case *InfixExpression:
+		obj.set("left", n.Left)
+		obj["operator"] = n.Operator
+		obj.set("right", n.Right)
+	case *IfExpression:
+		obj.set("condition", n.Condition)
+		obj.set("consequence", n.Consequence)
+		obj.set("alternative", n.Alternative)
+	case *TryExpression:
+		obj.set("block", n.Block)
+		obj.set("param", n.Param)
+		obj.set("catch", n.Catch)
+		obj.set("finally", n.Finally)
+	case *FunctionLiteral:
+		obj["parameters"] = encodeIdentifiers(n.Parameters)
+		obj.set("body", n.Body)
+		if n.Name != "" {
+			obj["name"] = n.Name
+		}
+		obj["generator"] = n.Generator
+	case *MacroLiteral:
+		obj["parameters"] = encodeIdentifiers(n.Parameters)
+		obj.set("body", n.Body)
+	case *CallExpression:
+		obj.set("function", n.Function)
+		obj["arguments"] = encodeExpressions(n.Arguments)
+		obj.setPos("rparen", n.Rparen)
+	case *ArrayLiteral:
+		obj["elements"] = encodeExpressions(n.Elements)
+		obj.setPos("rbracket", n.Rbracket)
+	case *IndexExpression:
+		obj.set("left", n.Left)
+		obj.set("index", n.Index)
+	case *HashLiteral:
+		pairs := []jsonObject{}
+		for _, p := range n.Pairs {
+			pair := jsonObject{}
+			pair.set("key", p.Key)
+			pair.set("value", p.Value)
+			pairs = append(pairs, pair)
+		}
+		obj["pairs"] = pairs
+		obj.setPos("rbrace", n.Rbrace)
+	}
+
+	return obj
+}
+
+// set sets the member name to the encoding of node, unless node is nil.
+func (obj jsonObject) set(name string, node Node) {
+	if isNil(node) {
+		return
+	}
+	obj[name] = encodeNode(node)
+}
+
+func (obj jsonObject) setPos(name string, pos token.Position) {
+	if pos.IsValid() {
+		obj[name] = jsonPos{Line: pos.Line, Column: pos.Column}
+	}
+}
+
+// isNil reports whether node is nil, including a nil pointer of a node type
+// like a missing *BlockStatement.
+func isNil(node Node) bool {
+	switch n := node.(type) {
+	case nil:
+		return true
+	case *Identifier:
+		return n == nil
+	case *BlockStatement:
+		return n == nil
+	}
+	return false
+}
+
+func encodeStatements(list []Statement) []jsonObject {
+	result := make([]jsonObject, 0, len(list))
+	for _, s := range list {
+		if s != nil {
+			result = append(result, encodeNode(s))
+		}
+	}
+	return result
+}
+
+func encodeExpressions(list []Expression) []jsonObject {
+	result := make([]jsonObject, 0, len(list))
+	for _, e := range list {
+		if e != nil {
+			result = append(result, encodeNode(e))
+		}
+	}
+	return result
+}
+
+func encodeIdentifiers(list []*Identifier) []jsonObject {
+	result := make([]jsonObject, 0, len(list))
+	for _, id := range list {
+		result = append(result, encodeNode(id))
+	}
+	return result
+}
+
+// decoding
+
+// jsonFields are the members of an encoded node, decoded one by one. The
+// first error is kept in err and makes the other calls do nothing.
+type jsonFields struct {
+	kind    string
+	members map[string]json.RawMessage
+	err     error
+}
+
+func decodeNode(data []byte) (Node, error) {
+	f := &jsonFields{}
+	if err := json.Unmarshal(data, &f.members); err != nil {
+		return nil, fmt.Errorf("ast: %s", err)
+	}
+	if f.members == nil {
+		return nil, fmt.Errorf("ast: node is null")
+	}
+	f.value("kind", &f.kind)
+	if f.err == nil && f.kind == "" {
+		return nil, fmt.Errorf("ast: node without kind")
+	}
+
+	pos := f.pos("pos")
+	tok := func(t token.TokenType, literal string) token.Token {
+		return token.Token{Type: t, Literal: literal, Pos: pos}
+	}
+
+	f.require(required[f.kind]...)
+
+	var node Node
+	switch f.kind {
+	case "Program":
+		node = &Program{Statements: f.statements("statements"), Comments: f.comments("comments")}
+	case "Comment":
+		c := &Comment{}
+		var text string
+		f.value("text", &text)
+		f.value("trailing", &c.Trailing)
+		c.Token = tok(token.COMMENT, text)
+		node = c
+
+	// statements
+	case "LetStatement":
+		node = &LetStatement{Token: tok(token.LET, "let"), Name: f.identifier("name"), Value: f.expression("value")}
+	case "ReturnStatement":
+		node = &ReturnStatement{Token: tok(token.RETURN, "return"), ReturnValue: f.expression("returnValue")}
+	case "ThrowStatement":
+		node = &ThrowStatement{Token: tok(token.THROW, "throw"), Value: f.expression("value")}
+	case "YieldStatement":
+		ys := &YieldStatement{Token: tok(token.YIELD, "yield"), Value: f.expression("value")}
+		f.value("delegate", &ys.Delegate)
+		node = ys
+	case "ExpressionStatement":
+		es := &ExpressionStatement{Expression: f.expression("expression")}
+		es.Token = startToken(es.Expression)
+		es.Token.Pos = pos
+		node = es
+	case "BlockStatement":
+		node = &BlockStatement{Token: tok(token.LBRACE, "{"), Statements: f.statements("statements"), Rbrace: f.pos("rbrace")}
+
+	// expressions
+	case "Identifier":
+		id := &Identifier{}
+		f.value("value", &id.Value)
+		id.Token = tok(token.IDENT, id.Value)
+		node = id
+	case "IntegerLiteral":
+		il := &IntegerLiteral{}
+		var literal string
+		f.value("value", &il.Value)
+		f.value("literal", &literal)
+		if literal == "" {
+			literal = fmt.Sprint(il.Value)
+		}
+		il.Token = tok(token.INT, literal)
+		node = il
+	case "StringLiteral":
+		sl := &StringLiteral{}
+		f.value("value", &sl.Value)
+		sl.Token = tok(token.STRING, sl.Value)
+		node = sl
+	case "Boolean":
+		b := &Boolean{}
+		f.value("value", &b.Value)
+		b.Token = tok(token.FALSE, "false")
+		if b.Value {
+			b.Token = tok(token.TRUE, "true")
+		}
+		node = b
+	case "PrefixExpression":
+		pe := &PrefixExpression{Right: f.expression("right")}
+		f.value("operator", &pe.Operator)
+		pe.Token = tok(token.TokenType(pe.Operator), pe.Operator)
+		node = pe
+	case "InfixExpression":
+		ie := &InfixExpression{Left: f.expression("left"), Right: f.expression("right")}
+		f.value("operator", &ie.Operator)
+		ie.Token = tok(token.TokenType(ie.Operator), ie.Operator)
+		node = ie
+	case "IfExpression":
+		node = &IfExpression{
+			Token:       tok(token.IF, "if"),
+			Condition:   f.expression("condition"),
+			Consequence: f.block("consequence"),
+			Alternative: f.block("alternative"),
+		}
+	case "TryExpression":
+		node = &TryExpression{
+			Token:   tok(token.TRY, "try"),
+			Block:   f.block("block"),
+			Param:   f.identifier("param"),
+			Catch:   f.block("catch"),
+			Finally: f.block("finally"),
+		}
+	case "FunctionLiteral":
+		fl := &FunctionLiteral{Token: tok(token.FUNCTION, "fn"), Parameters: f.identifiers("parameters"), Body: f.block("body")}
+		f.value("name", &fl.Name)
+		f.value("generator", &fl.Generator)
+		node = fl
+	case "MacroLiteral":
+		node = &MacroLiteral{Token: tok(token.MACRO, "macro"), Parameters: f.identifiers("parameters"), Body: f.block("body")}
+	case "CallExpression":
+		node = &CallExpression{
+			Token:     tok(token.LPAREN, "("),
+			Function:  f.expression("function"),
+			Arguments: f.expressions("arguments"),
+			Rparen:    f.pos("rparen"),
+		}
+	case "ArrayLiteral":
+		node = &ArrayLiteral{Token: tok(token.LBRACKET, "["), Elements: f.expressions("elements"), Rbracket: f.pos("rbracket")}
+	case "IndexExpression":
+		node = &IndexExpression{Token: tok(token.LBRACKET, "["), Left: f.expression("left"), Index: f.expression("index")}
+	case "HashLiteral":
+		node = &HashLiteral{Token: tok(token.LBRACE, "{"), Pairs: f.pairs("pairs"), Rbrace: f.pos("rbrace")}
+	default:
+		return nil, fmt.Errorf("ast: unknown node kind %q", f.kind)
+	}
+
+	if f.err != nil {
+		return nil, f.err
+	}
+	return node, nil
+}
+
+// required lists the members a node of each kind cannot do without.
+var required = map[string][]string{
+	"Comment":             {"text"},
+	"LetStatement":        {"name", "value"},
+	"ReturnStatement":     {"returnValue"},
+	"ThrowStatement":      {"value"},
+	"YieldStatement":      {"value"},
+	"ExpressionStatement": {"expression"},
+	"Identifier":          {"value"},
+	"IntegerLiteral":      {"value"},
+	"StringLiteral":       {"value"},
+	"Boolean":             {"value"},
+	"PrefixExpression":    {"operator", "right"},
+	"InfixExpression":     {"left", "operator", "right"},
+	"IfExpression":        {"condition", "consequence"},
+	"TryExpression":       {"block"},
+	"FunctionLiteral":     {"body"},
+	"MacroLiteral":        {"body"},
+	"CallExpression":      {"function"},
+	"IndexExpression":     {"left", "index"},
+}
+
+// require records an error for the first of names that is missing or null.
+func (f *jsonFields) require(names ...string) {
+	for _, name := range names {
+		if f.err == nil && isMissing(f.members[name]) {
+			f.err = fmt.Errorf("ast: %s without %s", f.kind, name)
+		}
+	}
+}
+
+func isMissing(raw json.RawMessage) bool {
+	return len(raw) == 0 || string(raw) == "null"
+}
+
+// value decodes the member name into v, leaving v alone if it is missing.
+func (f *jsonFields) value(name string, v interface{}) {
+	raw, ok := f.members[name]
+	if !ok || f.err != nil {
+		return
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		f.err = fmt.Errorf("ast: %s.%s: %s", f.kind, name, err)
+	}
+}
+
+func (f *jsonFields) pos(name string) token.Position {
+	var p jsonPos
+	f.value(name, &p)
+	return token.Position{Line: p.Line, Column: p.Column}
+}
+
+func (f *jsonFields) node(name string) Node {
+	raw, ok := f.members[name]
+	if !ok || f.err != nil {
+		return nil
+	}
+
+	node, err := decodeNode(raw)
+	if err != nil {
+		f.err = err
+	}
+	return node
+}
+
+func (f *jsonFields) nodes(name string) []Node {
+	var list []json.RawMessage
+	f.value(name, &list)
+	if f.err != nil {
+		return nil
+	}
+
+	nodes := make([]Node, len(list))
+	for i, raw := range list {
+		node, err := decodeNode(raw)
+		if err != nil {
+			f.err = err
+			return nil
+		}
+		nodes[i] = node
+	}
+	return nodes
+}
+
+// wrongKind records that the member name holds a node of the wrong kind.
+func (f *jsonFields) wrongKind(name, want string, node Node) {
+	if f.err == nil {
+		f.err = fmt.Errorf("ast: %s.%s: want %s, got %s", f.kind, name, want, kindOf(node))
+	}
+}
+
+func (f *jsonFields) expression(name string) Expression {
+	node := f.node(name)
+	if node == nil {
+		return nil
+	}
+
+	e, ok := node.(Expression)
+	if !ok {
+		f.wrongKind(name, "expression", node)
+	}
+	return e
+}
+
+func (f *jsonFields) identifier(name string) *Identifier {
+	node := f.node(name)
+	if node == nil {
+		return nil
+	}
+
+	id, ok := node.(*Identifier)
+	if !ok {
+		f.wrongKind(name, "Identifier", node)
+	}
+	return id
+}
+
+func (f *jsonFields) block(name string) *BlockStatement {
+	node := f.node(name)
+	if node == nil {
+		return nil
+	}
+
+	b, ok := node.(*BlockStatement)
+	if !ok {
+		f.wrongKind(name, "BlockStatement", node)
+	}
+	return b
+}
+
+func (f *jsonFields) statements(name string) []Statement {
+	list := []Statement{}
+	for _, node := range f.nodes(name) {
+		s, ok := node.(Statement)
+		if !ok {
+			f.wrongKind(name, "statement", node)
+			return nil
+		}
+		list = append(list, s)
+	}
+	return list
+}
+
+func (f *jsonFields) expressions(name string) []Expression {
+	list := []Expression{}
+	for _, node := range f.nodes(name) {
+		e, ok := node.(Expression)
+		if !ok {
+			f.wrongKind(name, "expression", node)
+			return nil
+		}
+		list = append(list, e)
+	}
+	return list
+}
+
+func (f *jsonFields) identifiers(name string) []*Identifier {
+	list := []*Identifier{}
+	for _, node := range f.nodes(name) {
+		id, ok := node.(*Identifier)
+		if !ok {
+			f.wrongKind(name, "Identifier", node)
+			return nil
+		}
+		list = append(list, id)
+	}
+	return list
+}
+
+func (f *jsonFields) comments(name string) []*Comment {
+	var list []*Comment
+	for _, node := range f.nodes(name) {
+		c, ok := node.(*Comment)
+		if !ok {
+			f.wrongKind(name, "Comment", node)
+			return nil
+		}
+		list = append(list, c)
+	}
+	return list
+}
+
+func (f *jsonFields) pairs(name string) []HashPair {
+	var list []struct {
+		Key   json.RawMessage `json:"key"`
+		Value json.RawMessage `json:"value"`
+	}
+	f.value(name, &list)
+
+	pairs := make([]HashPair, 0, len(list))
+	for _, pair := range list {
+		pf := &jsonFields{kind: f.kind, members: map[string]json.RawMessage{"key": pair.Key, "value": pair.Value}}
+		pf.require("key", "value")
+		key, value := pf.expression("key"), pf.expression("value")
+		if pf.err != nil {
+			if f.err == nil {
+				f.err = pf.err
+			}
+			return nil
+		}
+		pairs = append(pairs, HashPair{Key: key, Value: value})
+	}
+	return pairs
+}
+
+// startToken returns the first token of an expression, which the parser
+// gives to the statement of the expression.
+func startToken(e Expression) token.Token {
+	switch e := e.(type) {
+	case *InfixExpression:
+		return startToken(e.Left)
+	case *CallExpression:
+		return startToken(e.Function)
+	case *IndexExpression:
+		return startToken(e.Left)
+	case *Identifier:
+		return e.Token
+	case *IntegerLiteral:
+		return e.Token
+	case *StringLiteral:
+		return e.Token
+	case *Boolean:
+		return e.Token
+	case *PrefixExpression:
+		return e.Token
+	case *IfExpression:
+		return e.Token
+	case *TryExpression:
+		return e.Token
+	case *FunctionLiteral:
+		return e.Token
+	case *MacroLiteral:
+		return e.Token
+	case *ArrayLiteral:
+		return e.Token
+	case *HashLiteral:
+		return e.Token
+	}
+	return token.Token{}
+}
@@ -0,0 +1,151 @@
+package ast_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/smith-30/go-monkey/ast"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"statements", `let x = 5; return x; throw "e"; x + 1;`},
+		{"operators", `-a * b + !c == d < e; f > g != h / i - 010;`},
+		{"if", `if (x) { 1 } else { let y = 2; y }; if (z) { 3 }`},
+		{"try", `try { f() } catch (e) { e } finally { 1 }; try { 2 } finally { 3 }`},
+		{"functions", `let add = fn(a, b) { a + b }; add(1, 2); fn() {}()`},
+		{"generators", `let g = fn() { yield 1; yield* [2, 3]; }`},
+		{"macros", `let m = macro(x) { quote(unquote(x)) }`},
+		{"collections", `[1, "two", true][0]; {"a": 1, 2: [3], false: {}}["a"]`},
+		{"comments", "// header\nlet x = 1; // one\nx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := parse(t, tt.input)
+
+			data, err := ast.MarshalJSON(program)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			decoded, err := ast.UnmarshalProgram(data)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if decoded.String() != program.String() {
+				t.Errorf("want %q, but %q", program.String(), decoded.String())
+			}
+			if !reflect.DeepEqual(positions(decoded), positions(program)) {
+				t.Errorf("positions differ.\nwant %v\ngot  %v", positions(program), positions(decoded))
+			}
+			if !reflect.DeepEqual(decoded.Comments, program.Comments) {
+				t.Errorf("comments differ.\nwant %+v\ngot  %+v", program.Comments, decoded.Comments)
+			}
+
+			// the encoding is stable
+			again, err := ast.MarshalJSON(decoded)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(again) != string(data) {
+				t.Errorf("encoding changed.\nwant %s\ngot  %s", data, again)
+			}
+		})
+	}
+}
+
+func TestJSONTokens(t *testing.T) {
+	program := parse(t, `let f = fn(x) { -x * 2 }; f(1)`)
+
+	data, err := ast.MarshalJSON(program)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	decoded, err := ast.UnmarshalProgram(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// tokens are rebuilt from the kinds of the nodes
+	if !reflect.DeepEqual(decoded, program) {
+		t.Errorf("decoded program differs.\nwant %#v\ngot  %#v", program, decoded)
+	}
+}
+
+func TestJSONEncoding(t *testing.T) {
+	program := parse(t, `let x = [1];`)
+
+	data, err := ast.MarshalJSON(program.Statements[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	exp := `{"kind":"LetStatement","name":{"kind":"Identifier","pos":{"line":1,"column":5},"value":"x"},"pos":{"line":1,"column":1},"value":{"elements":[{"kind":"IntegerLiteral","literal":"1","pos":{"line":1,"column":10},"value":1}],"kind":"ArrayLiteral","pos":{"line":1,"column":9},"rbracket":{"line":1,"column":11}}}`
+	if string(data) != exp {
+		t.Errorf("want\n%s\nbut\n%s", exp, data)
+	}
+
+	node, err := ast.UnmarshalNode(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, ok := node.(*ast.LetStatement); !ok {
+		t.Errorf("want *ast.LetStatement, but %T", node)
+	}
+}
+
+func TestJSONErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+	}{
+		{"invalid", `{`, "ast: unexpected end of JSON input"},
+		{"null", `null`, "ast: node is null"},
+		{"no kind", `{"value": 1}`, "ast: node without kind"},
+		{"unknown kind", `{"kind": "WhileStatement"}`, `ast: unknown node kind "WhileStatement"`},
+		{"not a program", `{"kind": "Identifier", "value": "x"}`, "ast: want Program, got Identifier"},
+		{"wrong field type", `{"kind": "Program", "statements": [{"kind": "Identifier", "value": 1}]}`, "ast: Identifier.value: json: cannot unmarshal number into Go value of type string"},
+		{"wrong child kind", `{"kind": "Program", "statements": [{"kind": "Identifier", "value": "x"}]}`, "ast: Program.statements: want statement, got Identifier"},
+		{"statement as expression", `{"kind": "Program", "statements": [{"kind": "ExpressionStatement", "expression": {"kind": "ReturnStatement", "returnValue": {"kind": "Identifier", "value": "x"}}}]}`, "ast: ExpressionStatement.expression: want expression, got ReturnStatement"},
+		{"let without name", `{"kind": "Program", "statements": [{"kind": "LetStatement"}]}`, "ast: LetStatement without name"},
+		{"let without value", `{"kind": "Program", "statements": [{"kind": "LetStatement", "name": {"kind": "Identifier", "value": "x"}}]}`, "ast: LetStatement without value"},
+		{"identifier without value", `{"kind": "Program", "statements": [{"kind": "ExpressionStatement", "expression": {"kind": "Identifier"}}]}`, "ast: Identifier without value"},
+		{"infix without right", `{"kind": "Program", "statements": [{"kind": "ExpressionStatement", "expression": {"kind": "InfixExpression", "left": {"kind": "IntegerLiteral", "value": 1}, "operator": "+"}}]}`, "ast: InfixExpression without right"},
+		{"infix with null left", `{"kind": "Program", "statements": [{"kind": "ExpressionStatement", "expression": {"kind": "InfixExpression", "left": null}}]}`, "ast: InfixExpression without left"},
+		{"if without consequence", `{"kind": "Program", "statements": [{"kind": "ExpressionStatement", "expression": {"kind": "IfExpression", "condition": {"kind": "Boolean", "value": true}}}]}`, "ast: IfExpression without consequence"},
+		{"function without body", `{"kind": "Program", "statements": [{"kind": "ExpressionStatement", "expression": {"kind": "FunctionLiteral", "parameters": []}}]}`, "ast: FunctionLiteral without body"},
+		{"call without function", `{"kind": "Program", "statements": [{"kind": "ExpressionStatement", "expression": {"kind": "CallExpression", "arguments": []}}]}`, "ast: CallExpression without function"},
+		{"pair without value", `{"kind": "Program", "statements": [{"kind": "ExpressionStatement", "expression": {"kind": "HashLiteral", "pairs": [{"key": {"kind": "StringLiteral", "value": "a"}}]}}]}`, "ast: HashLiteral without value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ast.UnmarshalProgram([]byte(tt.input))
+			if err == nil {
+				t.Fatalf("want error %q, but got none", tt.exp)
+			}
+			if err.Error() != tt.exp {
+				t.Errorf("want %q, but %q", tt.exp, err.Error())
+			}
+		})
+	}
+}
+
+// positions lists the positions of the nodes of program in walk order.
+func positions(program *ast.Program) []string {
+	var out []string
+	ast.Inspect(program, func(n ast.Node) bool {
+		if n != nil {
+			out = append(out, strings.TrimPrefix(nodeName(n), "*ast.")+"@"+n.Pos().String())
+		}
+		return true
+	})
+	return out
+}
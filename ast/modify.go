@@ -0,0 +1,181 @@
+package ast
+
+// ModifierFunc returns the node that replaces node, or node itself.
+type ModifierFunc func(Node) Node
+
+// Modify rebuilds the tree rooted at node bottom-up: the children of a node
+// are modified before modifier is called with the node holding the results.
+// The given tree is not changed; composite nodes are copied and leaves the
+// modifier keeps are shared with it.
+//
+// A child replaced by nil or by a node of the wrong kind, like a statement
+// where an expression belongs, is kept as it is in the given tree, so the
+// result never holds nil where the given tree does not. In the same way,
+// identifiers naming a binding, such as function parameters, are kept
+// unless replaced by another identifier.
+func Modify(node Node, modifier ModifierFunc) Node {
+	switch n := node.(type) {
+	case *Program:
+		c := *n
+		c.Statements = modifyStatements(n.Statements, modifier)
+		node = &c
+
+	// statements
+	case *LetStatement:
+		c := *n
+		c.Name = modifyIdentifier(n.Name, modifier)
+		c.Value = modifyExpression(n.Value, modifier)
+		node = &c
+	case *ReturnStatement:
+		c := *n
+		c.ReturnValue = modifyExpression(n.ReturnValue, modifier)
+		node = &c
+	case *ThrowStatement:
+		c := *n
+		c.Value = modifyExpression(n.Value, modifier)
+		node = &c
+	case *YieldStatement:
+		c := *n
+		c.Value = modifyExpression(n.Value, modifier)
+		node = &c
+	case *ExpressionStatement:
+		c := *n
+		c.Expression = modifyExpression(n.Expression, modifier)
+		node = &c
+	case *BlockStatement:
+		c := *n
+		c.Statements = modifyStatements(n.Statements, modifier)
+		node = &c
+
+	// expressions
+	case *PrefixExpression:
+		c := *n
+		c.Right = modifyExpression(n.Right, modifier)
+		node = &c
+	case *InfixExpression:
+		c := *n
+		c.Left = modifyExpression(n.Left, modifier)
+		c.Right = modifyExpression(n.Right, modifier)
+		node = &c
+	case *IfExpression:
+		c := *n
+		c.Condition = modifyExpression(n.Condition, modifier)
+		c.Consequence = modifyBlock(n.Consequence, modifier)
+		c.Alternative = modifyBlock(n.Alternative, modifier)
+		node = &c
+	case *TryExpression:
+		c := *n
+		c.Block = modifyBlock(n.Block, modifier)
+		c.Param = modifyIdentifier(n.Param, modifier)
+		c.Catch = modifyBlock(n.Catch, modifier)
+		c.Finally = modifyBlock(n.Finally, modifier)
+		node = &c
+	case *FunctionLiteral:
+		c := *n
+		c.Parameters = make([]*Identifier, len(n.Parameters))
+		for i, p := range n.Parameters {
+			c.Parameters[i] = modifyIdentifier(p, modifier)
+		}
+		c.Body = modifyBlock(n.Body, modifier)
+		node = &c
+	case *MacroLiteral:
+		c := *n
+		c.Parameters = make([]*Identifier, len(n.Parameters))
+		for i, p := range n.Parameters {
+			c.Parameters[i] = modifyIdentifier(p, modifier)
+		}
+		c.Body = modifyBlock(n.Body, modifier)
+		node = &c
+	case *CallExpression:
+		c := *n
+		c.Function = modifyExpression(n.Function, modifier)
+		c.Arguments = modifyExpressions(n.Arguments, modifier)
+		node = &c
+	case *ArrayLiteral:
+		c := *n
+		c.Elements = modifyExpressions(n.Elements, modifier)
+		node = &c
+	case *IndexExpression:
+		c := *n
+		c.Left = modifyExpression(n.Left, modifier)
+		c.Index = modifyExpression(n.Index, modifier)
+		node = &c
+	case *HashLiteral:
+		c := *n
+		if n.Pairs != nil {
+			c.Pairs = make([]HashPair, len(n.Pairs))
+			for i, pair := range n.Pairs {
+				c.Pairs[i] = HashPair{
+					Key:   modifyExpression(pair.Key, modifier),
+					Value: modifyExpression(pair.Value, modifier),
+				}
+			}
+		}
+		node = &c
+	}
+
+	return modifier(node)
+}
+
+func modifyStatements(list []Statement, modifier ModifierFunc) []Statement {
+	if list == nil {
+		return nil
+	}
+
+	result := make([]Statement, len(list))
+	for i, s := range list {
+		result[i] = s
+		if s == nil {
+			continue
+		}
+		if r, ok := Modify(s, modifier).(Statement); ok && !isNil(r) {
+			result[i] = r
+		}
+	}
+	return result
+}
+
+func modifyExpressions(list []Expression, modifier ModifierFunc) []Expression {
+	if list == nil {
+		return nil
+	}
+
+	result := make([]Expression, len(list))
+	for i, e := range list {
+		result[i] = modifyExpression(e, modifier)
+	}
+	return result
+}
+
+func modifyExpression(e Expression, modifier ModifierFunc) Expression {
+	if e == nil {
+		return nil
+	}
+
+	if result, ok := Modify(e, modifier).(Expression); ok && !isNil(result) {
+		return result
+	}
+	return e
+}
+
+func modifyBlock(b *BlockStatement, modifier ModifierFunc) *BlockStatement {
+	if b == nil {
+		return nil
+	}
+
+	if result, ok := Modify(b, modifier).(*BlockStatement); ok && result != nil {
+		return result
+	}
+	return b
+}
+
+func modifyIdentifier(id *Identifier, modifier ModifierFunc) *Identifier {
+	if id == nil {
+		return nil
+	}
+
+	if result, ok := Modify(id, modifier).(*Identifier); ok && result != nil {
+		return result
+	}
+	return id
+}
@@ -0,0 +1,116 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/smith-30/go-monkey/ast"
+	"github.com/smith-30/go-monkey/token"
+)
+
+func TestModify(t *testing.T) {
+	// replaces 1 with 2
+	turnOneIntoTwo := func(n ast.Node) ast.Node {
+		il, ok := n.(*ast.IntegerLiteral)
+		if !ok || il.Value != 1 {
+			return n
+		}
+		return &ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "2"}, Value: 2}
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+	}{
+		{"let", `let x = 1;`, "let x = 2;"},
+		{"return", `fn() { return 1; }`, "fn() { return 2; }"},
+		{"throw", `throw 1;`, "throw 2;"},
+		{"yield", `fn() { yield 1; }`, "fn() { yield 2; }"},
+		{"prefix", `-1`, "(-2)"},
+		{"infix", `1 + 1`, "(2 + 2)"},
+		{"if", `if (1) { 1 } else { 1 }`, "if (2) { 2 } else { 2 }"},
+		{"try", `try { 1 } catch (e) { 1 } finally { 1 }`, "try { 2 } catch (e) { 2 } finally { 2 }"},
+		{"call", `f(1, g(1))`, "f(2, g(2))"},
+		{"array", `[1, [1]]`, "[2, [2]]"},
+		{"index", `a[1][1]`, "((a[2])[2])"},
+		{"hash", `{1: 1}`, "{2: 2}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := parse(t, tt.input)
+			before := program.String()
+
+			modified := ast.Modify(program, turnOneIntoTwo)
+			if modified.String() != tt.exp {
+				t.Errorf("want %q, but %q", tt.exp, modified.String())
+			}
+
+			// the original tree is left alone
+			if program.String() != before {
+				t.Errorf("input was changed to %q", program.String())
+			}
+		})
+	}
+}
+
+func TestModifyBottomUp(t *testing.T) {
+	// folds constant additions, which needs the operands folded first
+	fold := func(n ast.Node) ast.Node {
+		ie, ok := n.(*ast.InfixExpression)
+		if !ok || ie.Operator != "+" {
+			return n
+		}
+		l, lok := ie.Left.(*ast.IntegerLiteral)
+		r, rok := ie.Right.(*ast.IntegerLiteral)
+		if !lok || !rok {
+			return n
+		}
+		return &ast.IntegerLiteral{Token: ie.Token, Value: l.Value + r.Value}
+	}
+
+	modified := ast.Modify(parse(t, `f(1 + 2 + 3, x + 1)`), fold)
+
+	arg := modified.(*ast.Program).Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression).Arguments[0]
+	il, ok := arg.(*ast.IntegerLiteral)
+	if !ok || il.Value != 6 {
+		t.Errorf("want 6, but %s", arg)
+	}
+}
+
+func TestModifyWrongKind(t *testing.T) {
+	// statements cannot replace expressions and parameters must stay
+	// identifiers, so the original children are kept
+	modified := ast.Modify(parse(t, `fn(x) { x }`), func(n ast.Node) ast.Node {
+		if id, ok := n.(*ast.Identifier); ok && id.Value == "x" {
+			return &ast.ReturnStatement{}
+		}
+		return n
+	})
+
+	fn := modified.(*ast.Program).Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if fn.Parameters[0].Value != "x" {
+		t.Errorf("parameter was replaced by %v", fn.Parameters[0])
+	}
+	if expr := fn.Body.Statements[0].(*ast.ExpressionStatement).Expression; expr.String() != "x" {
+		t.Errorf("want %q, but %v", "x", expr)
+	}
+	if got := modified.String(); got != "fn(x) { x }" {
+		t.Errorf("want %q, but %q", "fn(x) { x }", got)
+	}
+}
+
+func TestModifyNil(t *testing.T) {
+	// nil, typed or not, is not a replacement either
+	for _, replacement := range []ast.Node{nil, (*ast.Identifier)(nil), (*ast.BlockStatement)(nil)} {
+		modified := ast.Modify(parse(t, `if (x) { y }; z`), func(n ast.Node) ast.Node {
+			if _, ok := n.(*ast.Program); ok {
+				return n
+			}
+			return replacement
+		})
+
+		if got := modified.String(); got != "if (x) { y }; z" {
+			t.Errorf("%#v: want %q, but %q", replacement, "if (x) { y }; z", got)
+		}
+	}
+}
@@ -1,11 +1,16 @@
 package ast
 
-import "bytes"
+import (
+	"bytes"
+
+	"github.com/smith-30/go-monkey/token"
+)
 
 type (
 	// Program is AST's root node
 	Program struct {
 		Statements []Statement
+		Comments   []*Comment // in source order
 	}
 )
 
@@ -18,12 +23,38 @@ func (p *Program) TokenLiteral() string {
 	}
 }
 
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Position{}
+}
+
 func (p *Program) String() string {
 	var out bytes.Buffer
 
-	for _, s := range p.Statements {
-		out.WriteString(s.String())
-	}
+	writeStatements(&out, p.Statements)
 
 	return out.String()
 }
+
+type (
+	// Comment is a line comment. Comments are not part of the tree, the
+	// parser collects them in Program.Comments.
+	Comment struct {
+		Token    token.Token // expects token.COMMENT, the literal starts with //
+		Trailing bool        // code precedes the comment on its line
+	}
+)
+
+func (c *Comment) TokenLiteral() string {
+	return c.Token.Literal
+}
+
+func (c *Comment) Pos() token.Position {
+	return c.Token.Pos
+}
+
+func (c *Comment) String() string {
+	return c.Token.Literal
+}
@@ -0,0 +1,164 @@
+package ast
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order: It starts by calling
+// v.Visit(node); node must not be nil. If the visitor w returned by
+// v.Visit(node) is not nil, Walk is invoked recursively with visitor
+// w for each of the non-nil children of node, followed by a call of
+// w.Visit(nil).
+//
+// Children are visited in source order. The pairs of a hash literal are
+// visited key first.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		walkStatements(v, n.Statements)
+
+	// statements
+	case *LetStatement:
+		if n.Name != nil {
+			Walk(v, n.Name)
+		}
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+	case *ReturnStatement:
+		if n.ReturnValue != nil {
+			Walk(v, n.ReturnValue)
+		}
+	case *ThrowStatement:
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+	case *YieldStatement:
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+	case *ExpressionStatement:
+		if n.Expression != nil {
+			Walk(v, n.Expression)
+		}
+	case *BlockStatement:
+		walkStatements(v, n.Statements)
+
+	// expressions
+	case *Identifier, *IntegerLiteral, *StringLiteral, *Boolean:
+		// leaves
+	case *PrefixExpression:
+		if n.Right != nil {
+			Walk(v, n.Right)
+		}
+	case *InfixExpression:
+		if n.Left != nil {
+			Walk(v, n.Left)
+		}
+		if n.Right != nil {
+			Walk(v, n.Right)
+		}
+	case *IfExpression:
+		if n.Condition != nil {
+			Walk(v, n.Condition)
+		}
+		if n.Consequence != nil {
+			Walk(v, n.Consequence)
+		}
+		if n.Alternative != nil {
+			Walk(v, n.Alternative)
+		}
+	case *TryExpression:
+		if n.Block != nil {
+			Walk(v, n.Block)
+		}
+		if n.Param != nil {
+			Walk(v, n.Param)
+		}
+		if n.Catch != nil {
+			Walk(v, n.Catch)
+		}
+		if n.Finally != nil {
+			Walk(v, n.Finally)
+		}
+	case *FunctionLiteral:
+		for _, p := range n.Parameters {
+			Walk(v, p)
+		}
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+	case *MacroLiteral:
+		for _, p := range n.Parameters {
+			Walk(v, p)
+		}
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+	case *CallExpression:
+		if n.Function != nil {
+			Walk(v, n.Function)
+		}
+		walkExpressions(v, n.Arguments)
+	case *ArrayLiteral:
+		walkExpressions(v, n.Elements)
+	case *IndexExpression:
+		if n.Left != nil {
+			Walk(v, n.Left)
+		}
+		if n.Index != nil {
+			Walk(v, n.Index)
+		}
+	case *HashLiteral:
+		for _, pair := range n.Pairs {
+			if pair.Key != nil {
+				Walk(v, pair.Key)
+			}
+			if pair.Value != nil {
+				Walk(v, pair.Value)
+			}
+		}
+	}
+
+	v.Visit(nil)
+}
+
+func walkStatements(v Visitor, list []Statement) {
+	for _, s := range list {
+		if s != nil {
+			Walk(v, s)
+		}
+	}
+}
+
+func walkExpressions(v Visitor, list []Expression) {
+	for _, e := range list {
+		if e != nil {
+			Walk(v, e)
+		}
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order: It starts by calling
+// f(node); node must not be nil. If f returns true, Inspect invokes f
+// recursively for each of the non-nil children of node, followed by a
+// call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
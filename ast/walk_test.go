@@ -0,0 +1,166 @@
+package ast_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/smith-30/go-monkey/ast"
+	"github.com/smith-30/go-monkey/lexer"
+	"github.com/smith-30/go-monkey/parser"
+)
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parse errors: %q", p.Errors())
+	}
+	return program
+}
+
+// nodeName describes a node by its type and, for leaves, its literal.
+func nodeName(n ast.Node) string {
+	name := strings.TrimPrefix(fmt.Sprintf("%T", n), "*ast.")
+	switch n := n.(type) {
+	case *ast.Identifier, *ast.IntegerLiteral, *ast.StringLiteral, *ast.Boolean:
+		return name + " " + n.TokenLiteral()
+	case *ast.InfixExpression:
+		return name + " " + n.Operator
+	}
+	return name
+}
+
+func TestInspect(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   []string
+	}{
+		{
+			name:  "let",
+			input: `let x = -1 + 2;`,
+			exp:   []string{"Program", "LetStatement", "Identifier x", "InfixExpression +", "PrefixExpression", "IntegerLiteral 1", "IntegerLiteral 2"},
+		},
+		{
+			name:  "function",
+			input: `fn(a, b) { return a; }`,
+			exp:   []string{"Program", "ExpressionStatement", "FunctionLiteral", "Identifier a", "Identifier b", "BlockStatement", "ReturnStatement", "Identifier a"},
+		},
+		{
+			name:  "if",
+			input: `if (true) { 1 } else { "a" }`,
+			exp:   []string{"Program", "ExpressionStatement", "IfExpression", "Boolean true", "BlockStatement", "ExpressionStatement", "IntegerLiteral 1", "BlockStatement", "ExpressionStatement", "StringLiteral a"},
+		},
+		{
+			name:  "call and index",
+			input: `f(xs[0], [1])`,
+			exp:   []string{"Program", "ExpressionStatement", "CallExpression", "Identifier f", "IndexExpression", "Identifier xs", "IntegerLiteral 0", "ArrayLiteral", "IntegerLiteral 1"},
+		},
+		{
+			name:  "hash",
+			input: `{"b": 1, "a": x}`,
+			exp:   []string{"Program", "ExpressionStatement", "HashLiteral", "StringLiteral b", "IntegerLiteral 1", "StringLiteral a", "Identifier x"},
+		},
+		{
+			name:  "try",
+			input: `try { throw 1; } catch (e) { e } finally { 2 }`,
+			exp:   []string{"Program", "ExpressionStatement", "TryExpression", "BlockStatement", "ThrowStatement", "IntegerLiteral 1", "Identifier e", "BlockStatement", "ExpressionStatement", "Identifier e", "BlockStatement", "ExpressionStatement", "IntegerLiteral 2"},
+		},
+		{
+			name:  "yield",
+			input: `fn() { yield* x; }`,
+			exp:   []string{"Program", "ExpressionStatement", "FunctionLiteral", "BlockStatement", "YieldStatement", "Identifier x"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			ast.Inspect(parse(t, tt.input), func(n ast.Node) bool {
+				if n != nil {
+					got = append(got, nodeName(n))
+				}
+				return true
+			})
+
+			if !reflect.DeepEqual(got, tt.exp) {
+				t.Errorf("want %q, but %q", tt.exp, got)
+			}
+		})
+	}
+}
+
+func TestInspectPrune(t *testing.T) {
+	program := parse(t, `let f = fn(x) { y }; z`)
+
+	var idents []string
+	ast.Inspect(program, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Identifier); ok {
+			idents = append(idents, id.Value)
+		}
+		// do not descend into functions
+		_, fn := n.(*ast.FunctionLiteral)
+		return !fn
+	})
+
+	if exp := []string{"f", "z"}; !reflect.DeepEqual(idents, exp) {
+		t.Errorf("want %q, but %q", exp, idents)
+	}
+}
+
+type depthVisitor struct {
+	depth int
+	out   *[]string
+}
+
+func (v depthVisitor) Visit(n ast.Node) ast.Visitor {
+	if n == nil {
+		*v.out = append(*v.out, strings.Repeat(" ", v.depth-1)+"end")
+		return nil
+	}
+	*v.out = append(*v.out, strings.Repeat(" ", v.depth)+nodeName(n))
+	return depthVisitor{depth: v.depth + 1, out: v.out}
+}
+
+func TestWalk(t *testing.T) {
+	var out []string
+	ast.Walk(depthVisitor{out: &out}, parse(t, `!a`))
+
+	exp := []string{
+		"Program",
+		" ExpressionStatement",
+		"  PrefixExpression",
+		"   Identifier a",
+		"   end",
+		"  end",
+		" end",
+		"end",
+	}
+	if !reflect.DeepEqual(out, exp) {
+		t.Errorf("want %q, but %q", exp, out)
+	}
+}
+
+func TestWalkIncompleteTree(t *testing.T) {
+	// nodes built by hand may lack children
+	program := &ast.Program{Statements: []ast.Statement{
+		&ast.LetStatement{Name: &ast.Identifier{Value: "x"}},
+		&ast.ExpressionStatement{Expression: &ast.IfExpression{}},
+		&ast.ExpressionStatement{Expression: &ast.TryExpression{}},
+	}}
+
+	count := 0
+	ast.Inspect(program, func(n ast.Node) bool {
+		if n != nil {
+			count++
+		}
+		return true
+	})
+
+	if count != 7 {
+		t.Errorf("want %d nodes, but %d", 7, count)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunAST(t *testing.T) {
+	const usage = "usage: monkey ast --json|--dot [file]\n"
+
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		status int
+		stdout string // prefix of the standard output
+		stderr string // prefix of the standard error
+	}{
+		{name: "json", args: []string{"--json"}, stdin: "let x = 1;", stdout: "{\n  \"kind\": \"Program\",\n"},
+		{name: "dot", args: []string{"--dot"}, stdin: "let x = 1;", stdout: "digraph AST {\n"},
+		{name: "no format", stdin: "1", status: 2, stderr: usage},
+		{name: "both formats", args: []string{"--json", "--dot"}, stdin: "1", status: 2, stderr: usage},
+		{name: "two files", args: []string{"--json", "a.mk", "b.mk"}, status: 2, stderr: usage},
+		{name: "unknown flag", args: []string{"--yaml"}, status: 2, stderr: "flag provided but not defined: -yaml\n" + usage},
+		{name: "parse error", args: []string{"--json"}, stdin: "let =", status: 1, stderr: "monkey ast: parse error: "},
+		{name: "missing file", args: []string{"--dot", "missing.mk"}, status: 1, stderr: "monkey ast: open missing.mk: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			status := runAST(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+
+			if status != tt.status {
+				t.Errorf("want status %d, but %d", tt.status, status)
+			}
+			if !strings.HasPrefix(stdout.String(), tt.stdout) || (tt.stdout == "") != (stdout.Len() == 0) {
+				t.Errorf("want %q, but %q", tt.stdout, stdout.String())
+			}
+			if !strings.HasPrefix(stderr.String(), tt.stderr) || (tt.stderr == "") != (stderr.Len() == 0) {
+				t.Errorf("want %q, but %q", tt.stderr, stderr.String())
+			}
+		})
+	}
+}
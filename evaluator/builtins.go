@@ -2,16 +2,18 @@ package evaluator
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/smith-30/go-monkey/object"
 )
 
-//
 // monkey's array is static so push and rest do copy original array and return
-//
 var builtins = map[string]*object.Builtin{
 	"len": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Name:  "len",
+		Arity: 1,
+		Doc:   "len(x) returns the number of elements of an array or bytes of a string.",
+		Fn: func(ctx object.CallContext, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1", len(args))
 			}
@@ -27,7 +29,10 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 	"first": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Name:  "first",
+		Arity: 1,
+		Doc:   "first(array) returns the first element of array, or null if it is empty.",
+		Fn: func(ctx object.CallContext, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1", len(args))
 			}
@@ -45,7 +50,10 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 	"last": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Name:  "last",
+		Arity: 1,
+		Doc:   "last(array) returns the last element of array, or null if it is empty.",
+		Fn: func(ctx object.CallContext, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1", len(args))
 			}
@@ -64,7 +72,10 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 	"rest": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Name:  "rest",
+		Arity: 1,
+		Doc:   "rest(array) returns a new array without the first element of array.",
+		Fn: func(ctx object.CallContext, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("wrong number of arguments. got=%d, want=1", len(args))
 			}
@@ -88,7 +99,10 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 	"push": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Name:  "push",
+		Arity: 2,
+		Doc:   "push(array, x) returns a new array with x appended to array.",
+		Fn: func(ctx object.CallContext, args ...object.Object) object.Object {
 			if len(args) != 2 {
 				return newError("wrong number of arguments. got=%d, want=2", len(args))
 			}
@@ -107,11 +121,182 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 	"puts": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		Name:  "puts",
+		Arity: object.Variadic,
+		Doc:   "puts(x...) prints each argument on a line of its own.",
+		Fn: func(ctx object.CallContext, args ...object.Object) object.Object {
+			if err := ctx.Check(object.OutputAccess, ""); err != nil {
+				return err
+			}
+
+			out := ctx.Stdout()
 			for _, v := range args {
-				fmt.Println(v.Inspect())
+				fmt.Fprintln(out, v.Inspect())
 			}
 			return NULL
 		},
 	},
+	"repr": NewBuiltin("repr", 1,
+		"repr(x) returns x as a string the way the REPL shows it, with strings in quotes.",
+		builtinRepr),
+	"error": &object.Builtin{
+		Name:  "error",
+		Arity: object.Variadic,
+		Doc:   "error(message[, cause]) returns a soft error value wrapping cause.",
+		Fn: func(ctx object.CallContext, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+
+			msg, ok := args[0].(*object.String)
+			if !ok {
+				return newError("argument to `error` must be String, got=%s", args[0].Type())
+			}
+
+			err := &object.Error{Message: msg.Value, Soft: true}
+			if len(args) == 2 {
+				cause, ok := args[1].(*object.Error)
+				if !ok {
+					return newError("cause given to `error` must be Error, got=%s", args[1].Type())
+				}
+				err.Cause = cause
+			}
+
+			return err
+		},
+	},
+	"is_error": &object.Builtin{
+		Name:  "is_error",
+		Arity: 1,
+		Doc:   "is_error(x) reports whether x is an error value.",
+		Fn: func(ctx object.CallContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			return nativeBoolToBooleanObject(args[0].Type() == object.ERROR_OBJ)
+		},
+	},
+	"unwrap": &object.Builtin{
+		Name:  "unwrap",
+		Arity: 1,
+		Doc:   "unwrap(err) returns the cause of err, or null if there is none.",
+		Fn: func(ctx object.CallContext, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			err, ok := args[0].(*object.Error)
+			if !ok {
+				return newError("argument to `unwrap` must be Error, got=%s", args[0].Type())
+			}
+
+			if err.Cause == nil {
+				return NULL
+			}
+
+			return err.Cause
+		},
+	},
+	"map": NewBuiltin("map", 2,
+		"map(collection, f) returns an array of f(x) for each element, or f(key, value) for each pair of a hash.",
+		builtinMap),
+	"filter": NewBuiltin("filter", 2,
+		"filter(collection, f) returns the elements, or pairs of a hash, for which f is truthy.",
+		builtinFilter),
+	"reduce": NewBuiltin("reduce", 3,
+		"reduce(array, initial, f) folds array from the left with f(accumulated, x).",
+		builtinReduce),
+	"find": NewBuiltin("find", 2,
+		"find(array, f) returns the first element for which f is truthy, or null.",
+		builtinFind),
+	"any": NewBuiltin("any", 2,
+		"any(collection, f) reports whether f is truthy for some element.",
+		builtinAny),
+	"all": NewBuiltin("all", 2,
+		"all(collection, f) reports whether f is truthy for every element.",
+		builtinAll),
+	"each": NewBuiltin("each", 2,
+		"each(collection, f) calls f for each element, or with key and value for each pair of a hash.",
+		builtinEach),
+	"sort": NewBuiltin("sort", object.Variadic,
+		"sort(array[, less]) returns a sorted copy of array. less(a, b) reports whether a sorts before b.",
+		builtinSort),
+	"sort_by": NewBuiltin("sort_by", 2,
+		"sort_by(array, f) returns a copy of array sorted by f(x).",
+		builtinSortBy),
+	"spawn": NewBuiltin("spawn", object.Variadic,
+		"spawn(f, args...) calls f(args...) on a task of its own and returns the task.",
+		builtinSpawn),
+	"await": NewBuiltin("await", 1,
+		"await(task) waits for task to finish and returns its result. An error of the task is raised again.",
+		builtinAwait),
+	"channel": NewBuiltin("channel", object.Variadic,
+		"channel([capacity]) returns a new channel buffering up to capacity values.",
+		builtinChannel),
+	"send": NewBuiltin("send", 2,
+		"send(channel, x) sends x on channel, waiting while its buffer is full.",
+		builtinSend),
+	"receive": NewBuiltin("receive", 1,
+		"receive(channel) waits for a value on channel. It returns null once channel is closed and drained.",
+		builtinReceive),
+	"close": NewBuiltin("close", 1,
+		"close(channel) closes channel, no more values may be sent on it.",
+		builtinClose),
+	"range": NewBuiltin("range", object.Variadic,
+		"range([start, ]stop[, step]) returns an iterator over the integers from start, 0 by default, up to but not including stop.",
+		builtinRange),
+	"take": NewBuiltin("take", 2,
+		"take(xs, n) returns an iterator over the first n elements of xs.",
+		builtinTake),
+	"zip": NewBuiltin("zip", object.Variadic,
+		"zip(xs...) returns an iterator over arrays holding the next element of each argument, up to the end of the shortest.",
+		builtinZip),
+	"enumerate": NewBuiltin("enumerate", 1,
+		"enumerate(xs) returns an iterator over [index, x] for each element of xs.",
+		builtinEnumerate),
+	"next": NewBuiltin("next", 1,
+		"next(iterator) returns the next value of iterator, or null once it is exhausted.",
+		builtinNext),
+	"collect": NewBuiltin("collect", 1,
+		"collect(xs) returns an array of the values of xs.",
+		builtinCollect),
+	"select": NewBuiltin("select", object.Variadic,
+		"select(channels[, timeout]) receives from the first ready channel and returns [index, value], or null after timeout milliseconds.",
+		builtinSelect),
+	"clock": NewBuiltin("clock", 0,
+		"clock() returns the milliseconds elapsed since the interpreter started.",
+		builtinClock),
+}
+
+// started is read through the monotonic clock, so clock never goes back.
+var started = time.Now()
+
+func builtinClock(ctx object.CallContext, args ...object.Object) object.Object {
+	return &object.Integer{Value: int64(time.Since(started) / time.Millisecond)}
+}
+
+func builtinRepr(ctx object.CallContext, args ...object.Object) object.Object {
+	return &object.String{Value: object.Repr(args[0])}
+}
+
+// newBuiltins returns a builtin table of its own that starts out with the
+// default builtins.
+func newBuiltins() map[string]*object.Builtin {
+	table := make(map[string]*object.Builtin, len(builtins))
+	for name, b := range builtins {
+		table[name] = b
+	}
+
+	return table
+}
+
+func (ev *Evaluator) builtin(name string) (*object.Builtin, bool) {
+	if ev.builtins == nil {
+		b, ok := builtins[name]
+		return b, ok
+	}
+
+	b, ok := ev.builtins[name]
+	return b, ok
 }
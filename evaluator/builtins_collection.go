@@ -0,0 +1,303 @@
+package evaluator
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/smith-30/go-monkey/object"
+)
+
+// Higher-order builtins over arrays and hashes. They never modify their
+// arguments. An error returned by a callback stops the iteration and is
+// returned as is. Given an iterator, map and filter return iterators and
+// the others consume it.
+
+func builtinMap(ctx object.CallContext, args ...object.Object) object.Object {
+	if it, ok := args[0].(*object.Iterator); ok && isCallable(args[1]) {
+		return mapIterator(it, args[1])
+	}
+
+	calls, err := callbackArgs("map", args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	result := make([]object.Object, 0, len(calls))
+	for _, callArgs := range calls {
+		v := ctx.Apply(args[1], callArgs...)
+		if isError(v) {
+			return v
+		}
+		result = append(result, v)
+	}
+
+	return &object.Array{Elements: result}
+}
+
+func builtinFilter(ctx object.CallContext, args ...object.Object) object.Object {
+	if it, ok := args[0].(*object.Iterator); ok && isCallable(args[1]) {
+		return filterIterator(it, args[1])
+	}
+
+	calls, err := callbackArgs("filter", args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	kept := make([]object.Object, 0, len(calls))
+	hash := &object.Hash{}
+	for _, callArgs := range calls {
+		v := ctx.Apply(args[1], callArgs...)
+		if isError(v) {
+			return v
+		}
+		if !isTruth(v) {
+			continue
+		}
+
+		if len(callArgs) == 2 {
+			hash.Set(callArgs[0].(object.Hashable), callArgs[1])
+		} else {
+			kept = append(kept, callArgs[0])
+		}
+	}
+
+	if args[0].Type() == object.HASH_OBJ {
+		return hash
+	}
+	return &object.Array{Elements: kept}
+}
+
+func builtinReduce(ctx object.CallContext, args ...object.Object) object.Object {
+	it, err := iterate("first argument to `reduce`", args[0])
+	if err != nil {
+		return err
+	}
+	if !isCallable(args[2]) {
+		return newError("third argument to `reduce` must be Function, got=%s", args[2].Type())
+	}
+
+	acc := args[1]
+	for e, ok := it.Next(ctx); ok; e, ok = it.Next(ctx) {
+		if isError(e) {
+			return e
+		}
+		acc = ctx.Apply(args[2], acc, e)
+		if isError(acc) {
+			return acc
+		}
+	}
+
+	return acc
+}
+
+func builtinFind(ctx object.CallContext, args ...object.Object) object.Object {
+	it, err := iterate("first argument to `find`", args[0])
+	if err != nil {
+		return err
+	}
+	if !isCallable(args[1]) {
+		return newError("second argument to `find` must be Function, got=%s", args[1].Type())
+	}
+
+	for e, ok := it.Next(ctx); ok; e, ok = it.Next(ctx) {
+		if isError(e) {
+			return e
+		}
+		v := ctx.Apply(args[1], e)
+		if isError(v) {
+			return v
+		}
+		if isTruth(v) {
+			return e
+		}
+	}
+
+	return NULL
+}
+
+func builtinAny(ctx object.CallContext, args ...object.Object) object.Object {
+	return matchAny(ctx, "any", args, true)
+}
+
+func builtinAll(ctx object.CallContext, args ...object.Object) object.Object {
+	return matchAny(ctx, "all", args, false)
+}
+
+// matchAny reports whether the callback returns a value whose truth is want
+// for some element. all is the negation of matching a falsy result.
+func matchAny(ctx object.CallContext, name string, args []object.Object, want bool) object.Object {
+	calls, err := callbackArgs(name, args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	for _, callArgs := range calls {
+		v := ctx.Apply(args[1], callArgs...)
+		if isError(v) {
+			return v
+		}
+		if isTruth(v) == want {
+			return nativeBoolToBooleanObject(want)
+		}
+	}
+
+	return nativeBoolToBooleanObject(!want)
+}
+
+func builtinEach(ctx object.CallContext, args ...object.Object) object.Object {
+	if it, ok := args[0].(*object.Iterator); ok && isCallable(args[1]) {
+		for e, ok := it.Next(ctx); ok; e, ok = it.Next(ctx) {
+			if isError(e) {
+				return e
+			}
+			if v := ctx.Apply(args[1], e); isError(v) {
+				return v
+			}
+		}
+		return NULL
+	}
+
+	calls, err := callbackArgs("each", args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	for _, callArgs := range calls {
+		if v := ctx.Apply(args[1], callArgs...); isError(v) {
+			return v
+		}
+	}
+
+	return NULL
+}
+
+func builtinSort(ctx object.CallContext, args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("first argument to `sort` must be Array, got=%s", args[0].Type())
+	}
+
+	elems := make([]object.Object, len(arr.Elements))
+	copy(elems, arr.Elements)
+
+	if len(args) == 1 {
+		return sortObjects(elems, elems)
+	}
+
+	if !isCallable(args[1]) {
+		return newError("second argument to `sort` must be Function, got=%s", args[1].Type())
+	}
+
+	var failed object.Object
+	sort.SliceStable(elems, func(i, j int) bool {
+		if failed != nil {
+			return false
+		}
+		v := ctx.Apply(args[1], elems[i], elems[j])
+		if isError(v) {
+			failed = v
+			return false
+		}
+		return isTruth(v)
+	})
+	if failed != nil {
+		return failed
+	}
+
+	return &object.Array{Elements: elems}
+}
+
+func builtinSortBy(ctx object.CallContext, args ...object.Object) object.Object {
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("first argument to `sort_by` must be Array, got=%s", args[0].Type())
+	}
+	if !isCallable(args[1]) {
+		return newError("second argument to `sort_by` must be Function, got=%s", args[1].Type())
+	}
+
+	elems := make([]object.Object, len(arr.Elements))
+	keys := make([]object.Object, len(arr.Elements))
+	for i, e := range arr.Elements {
+		k := ctx.Apply(args[1], e)
+		if isError(k) {
+			return k
+		}
+		elems[i] = e
+		keys[i] = k
+	}
+
+	return sortObjects(elems, keys)
+}
+
+// sortObjects sorts elems stably by the natural order of keys.
+func sortObjects(elems, keys []object.Object) object.Object {
+	for i := 1; i < len(keys); i++ {
+		if _, ok := compareObjects(keys[0], keys[i]); !ok {
+			return newError("cannot compare %s and %s", keys[0].Type(), keys[i].Type())
+		}
+	}
+
+	idx := make([]int, len(elems))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		c, _ := compareObjects(keys[idx[i]], keys[idx[j]])
+		return c < 0
+	})
+
+	sorted := make([]object.Object, len(elems))
+	for i, k := range idx {
+		sorted[i] = elems[k]
+	}
+
+	return &object.Array{Elements: sorted}
+}
+
+// compareObjects orders integers numerically and strings bytewise.
+func compareObjects(a, b object.Object) (int, bool) {
+	switch {
+	case isInteger(a) && isInteger(b):
+		return toBigInt(a).Cmp(toBigInt(b)), true
+	case a.Type() == object.STRING_OBJ && b.Type() == object.STRING_OBJ:
+		return strings.Compare(a.(*object.String).Value, b.(*object.String).Value), true
+	default:
+		return 0, false
+	}
+}
+
+func isCallable(obj object.Object) bool {
+	t := obj.Type()
+	return t == object.FUNCTION_OBJ || t == object.BUILTIN_OBJ
+}
+
+// callbackArgs returns the arguments for each callback of an iteration over
+// coll: the element of an array, or the key and value of a hash pair.
+func callbackArgs(name string, coll, fn object.Object) ([][]object.Object, *object.Error) {
+	if !isCallable(fn) {
+		return nil, newError("second argument to `%s` must be Function, got=%s", name, fn.Type())
+	}
+
+	switch coll := coll.(type) {
+	case *object.Array:
+		calls := make([][]object.Object, len(coll.Elements))
+		for i, e := range coll.Elements {
+			calls[i] = []object.Object{e}
+		}
+		return calls, nil
+	case *object.Hash:
+		calls := make([][]object.Object, 0, coll.Len())
+		for _, pair := range coll.Pairs() {
+			calls = append(calls, []object.Object{pair.Key, pair.Value})
+		}
+		return calls, nil
+	default:
+		return nil, newError("first argument to `%s` must be Array or Hash, got=%s", name, coll.Type())
+	}
+}
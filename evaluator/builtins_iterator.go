@@ -0,0 +1,200 @@
+package evaluator
+
+import (
+	"github.com/smith-30/go-monkey/object"
+)
+
+// Lazy builtins over iterators. Arrays are accepted wherever an iterator
+// is; the results are iterators that compute their values only when asked.
+
+// iterate returns an iterator over the elements of an array or iterator.
+// arg describes obj in the error message, like "argument to `zip`".
+func iterate(arg string, obj object.Object) (*object.Iterator, *object.Error) {
+	switch obj := obj.(type) {
+	case *object.Iterator:
+		return obj, nil
+	case *object.Array:
+		i := 0
+		return &object.Iterator{Next: func(object.CallContext) (object.Object, bool) {
+			if i >= len(obj.Elements) {
+				return nil, false
+			}
+			i++
+			return obj.Elements[i-1], true
+		}}, nil
+	default:
+		return nil, newError("%s must be Array or Iterator, got=%s", arg, obj.Type())
+	}
+}
+
+func builtinRange(ctx object.CallContext, args ...object.Object) object.Object {
+	if len(args) < 1 || len(args) > 3 {
+		return newError("wrong number of arguments. got=%d, want=1 to 3", len(args))
+	}
+
+	bounds := make([]int64, len(args))
+	for i, arg := range args {
+		n, ok := arg.(*object.Integer)
+		if !ok {
+			return newError("arguments to `range` must be Integer, got=%s", arg.Type())
+		}
+		bounds[i] = n.Value
+	}
+
+	start, stop, step := int64(0), bounds[0], int64(1)
+	if len(bounds) > 1 {
+		start, stop = bounds[0], bounds[1]
+	}
+	if len(bounds) > 2 {
+		step = bounds[2]
+	}
+	if step == 0 {
+		return newError("step of `range` must not be 0")
+	}
+
+	next := start
+	return &object.Iterator{Next: func(object.CallContext) (object.Object, bool) {
+		if (step > 0 && next >= stop) || (step < 0 && next <= stop) {
+			return nil, false
+		}
+		v := next
+		next += step
+		// stop instead of wrapping around
+		if (step > 0 && next < v) || (step < 0 && next > v) {
+			next = stop
+		}
+		return &object.Integer{Value: v}, true
+	}}
+}
+
+func builtinTake(ctx object.CallContext, args ...object.Object) object.Object {
+	it, err := iterate("first argument to `take`", args[0])
+	if err != nil {
+		return err
+	}
+
+	n, ok := args[1].(*object.Integer)
+	if !ok || n.Value < 0 {
+		return newError("second argument to `take` must be a non-negative Integer, got=%s", args[1].Inspect())
+	}
+
+	left := n.Value
+	return &object.Iterator{Next: func(ctx object.CallContext) (object.Object, bool) {
+		if left <= 0 {
+			return nil, false
+		}
+		left--
+		return it.Next(ctx)
+	}}
+}
+
+func builtinZip(ctx object.CallContext, args ...object.Object) object.Object {
+	if len(args) < 1 {
+		return newError("wrong number of arguments. got=%d, want at least 1", len(args))
+	}
+
+	its := make([]*object.Iterator, len(args))
+	for i, arg := range args {
+		it, err := iterate("argument to `zip`", arg)
+		if err != nil {
+			return err
+		}
+		its[i] = it
+	}
+
+	// the shortest sequence ends the zip
+	return &object.Iterator{Next: func(ctx object.CallContext) (object.Object, bool) {
+		elems := make([]object.Object, len(its))
+		for i, it := range its {
+			v, ok := it.Next(ctx)
+			if !ok {
+				return nil, false
+			}
+			if isError(v) {
+				return v, true
+			}
+			elems[i] = v
+		}
+		return &object.Array{Elements: elems}, true
+	}}
+}
+
+func builtinEnumerate(ctx object.CallContext, args ...object.Object) object.Object {
+	it, err := iterate("argument to `enumerate`", args[0])
+	if err != nil {
+		return err
+	}
+
+	var i int64
+	return &object.Iterator{Next: func(ctx object.CallContext) (object.Object, bool) {
+		v, ok := it.Next(ctx)
+		if !ok || isError(v) {
+			return v, ok
+		}
+		i++
+		return &object.Array{Elements: []object.Object{&object.Integer{Value: i - 1}, v}}, true
+	}}
+}
+
+func builtinNext(ctx object.CallContext, args ...object.Object) object.Object {
+	it, ok := args[0].(*object.Iterator)
+	if !ok {
+		return newError("argument to `next` must be Iterator, got=%s", args[0].Type())
+	}
+
+	v, ok := it.Next(ctx)
+	if !ok {
+		return NULL
+	}
+	return v
+}
+
+func builtinCollect(ctx object.CallContext, args ...object.Object) object.Object {
+	it, err := iterate("argument to `collect`", args[0])
+	if err != nil {
+		return err
+	}
+
+	elems := []object.Object{}
+	for v, ok := it.Next(ctx); ok; v, ok = it.Next(ctx) {
+		if isError(v) {
+			return v
+		}
+		elems = append(elems, v)
+	}
+
+	return &object.Array{Elements: elems}
+}
+
+// mapIterator is the lazy form of map. fn is applied with the context of
+// whoever asks for the value, which may be another task than the one that
+// created the iterator.
+func mapIterator(it *object.Iterator, fn object.Object) *object.Iterator {
+	return &object.Iterator{Next: func(ctx object.CallContext) (object.Object, bool) {
+		v, ok := it.Next(ctx)
+		if !ok || isError(v) {
+			return v, ok
+		}
+		return ctx.Apply(fn, v), true
+	}}
+}
+
+// filterIterator is the lazy form of filter.
+func filterIterator(it *object.Iterator, fn object.Object) *object.Iterator {
+	return &object.Iterator{Next: func(ctx object.CallContext) (object.Object, bool) {
+		for v, ok := it.Next(ctx); ok; v, ok = it.Next(ctx) {
+			if isError(v) {
+				return v, true
+			}
+
+			keep := ctx.Apply(fn, v)
+			if isError(keep) {
+				return keep, true
+			}
+			if isTruth(keep) {
+				return v, true
+			}
+		}
+		return nil, false
+	}}
+}
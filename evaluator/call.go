@@ -0,0 +1,52 @@
+package evaluator
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/smith-30/go-monkey/object"
+)
+
+// callContext is the object.CallContext handed to builtins. Calls made
+// through it share the limits of the evaluation and stop together with it.
+type callContext struct {
+	ev *Evaluator
+}
+
+func (c callContext) Apply(fn object.Object, args ...object.Object) object.Object {
+	return c.ev.applyFunction(fn, args)
+}
+
+func (c callContext) Context() context.Context {
+	if c.ev.ctx == nil {
+		return context.Background()
+	}
+	return c.ev.ctx
+}
+
+func (c callContext) Stdout() io.Writer {
+	return c.ev.state().stdout
+}
+
+func (c callContext) Stderr() io.Writer {
+	return c.ev.state().stderr
+}
+
+func (c callContext) Check(capability object.Capability, resource string) *object.Error {
+	return c.ev.check(capability, resource)
+}
+
+// lockedWriter lets the tasks of an evaluation share a writer that is not
+// safe for concurrent use, like a bytes.Buffer. Stdout and Stderr share the
+// lock, as they are often the same writer.
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
@@ -0,0 +1,110 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/smith-30/go-monkey/lexer"
+	"github.com/smith-30/go-monkey/object"
+	"github.com/smith-30/go-monkey/parser"
+)
+
+// These tests are meant to be run with the race detector.
+
+const concurrency = 8
+
+func TestConcurrentInterpreters(t *testing.T) {
+	var wg sync.WaitGroup
+	for n := 0; n < concurrency; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+
+			interp := NewInterpreter()
+			interp.Register("n", 0, "", func(ctx object.CallContext, args ...object.Object) object.Object {
+				return &object.Integer{Value: int64(n)}
+			})
+
+			input := `
+let add = fn(x) { fn(y) { x + y } };
+let xs = map([1, 2, 3], add(n()));
+let r = try { 1 / 0 } catch (e) { e["message"] };
+[reduce(xs, 0, fn(a, b) { a + b }), r]`
+
+			result, err := interp.Run(context.Background(), input)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			exp := fmt.Sprintf(`[%d, "division by zero"]`, 6+3*n)
+			if result.Inspect() != exp {
+				t.Errorf("want %q, but %q", exp, result.Inspect())
+			}
+		}(n)
+	}
+	wg.Wait()
+}
+
+func TestConcurrentProgram(t *testing.T) {
+	p := parser.New(lexer.New(`
+let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } };
+let check = fn(n) { if (n > 10) { throw "too big" } else { n } };
+[fib(15), try { check(11) } catch (e) { e["position"] }, sort_by(["bb", "a"], len)]`))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatal(p.Errors())
+	}
+
+	var wg sync.WaitGroup
+	for n := 0; n < concurrency; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result := Eval(program, object.NewEnvironment())
+			if result.Inspect() != `[610, "3:35", ["a", "bb"]]` {
+				t.Errorf("unexpected result %q", result.Inspect())
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConcurrentRuns(t *testing.T) {
+	interp := NewInterpreter()
+	if _, err := interp.Run(context.Background(), `let counter = fn(x) { x + 1 };`); err != nil {
+		t.Fatal(err)
+	}
+
+	// runs of the same interpreter share the root environment
+	var wg sync.WaitGroup
+	for n := 0; n < concurrency; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+
+			// identifiers cannot contain digits
+			name := fmt.Sprintf("v_%c", 'a'+n)
+			input := fmt.Sprintf("let %s = counter(%d); %s", name, n, name)
+			result, err := interp.Run(context.Background(), input)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if result.Inspect() != fmt.Sprint(n+1) {
+				t.Errorf("want %d, but %s", n+1, result.Inspect())
+			}
+		}(n)
+	}
+	wg.Wait()
+
+	for n := 0; n < concurrency; n++ {
+		name := fmt.Sprintf("v_%c", 'a'+n)
+		if _, ok := interp.Env().Get(name); !ok {
+			t.Errorf("%s is not bound", name)
+		}
+	}
+}
@@ -1,74 +1,199 @@
 package evaluator
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
 
 	"github.com/smith-30/go-monkey/ast"
 	"github.com/smith-30/go-monkey/object"
 )
 
 var (
-	NULL = &object.Null{}
+	NULL = object.NULL
 
-	TRUE  = &object.Boolean{Value: true}
-	FALSE = &object.Boolean{Value: false}
+	TRUE  = object.TRUE
+	FALSE = object.FALSE
 )
 
+// Evaluator evaluates AST nodes. The zero value is ready to use and
+// resolves builtins from a table shared by all zero value Evaluators, which
+// is never modified.
+//
+// An Evaluator keeps the state of the evaluation in progress and must not
+// be used by several goroutines at once. The AST is only read, so a parsed
+// program may be evaluated by many Evaluators concurrently.
+type Evaluator struct {
+	// CheckedArithmetic reports integer overflow as an error instead of
+	// promoting the result to a BigInt.
+	CheckedArithmetic bool
+
+	Stdout io.Writer // where puts writes, os.Stdout if nil
+	Stderr io.Writer // where diagnostics are written, os.Stderr if nil
+
+	Limits Limits
+
+	// Policy restricts the builtins and resources scripts may use.
+	// Nil allows everything.
+	Policy *Policy
+
+	builtins map[string]*object.Builtin
+
+	// state of a single evaluation, see Interpreter.Run
+	ctx   context.Context
+	run   *evaluation // shared by all tasks, created on first use
+	depth int         // nesting of calls in the current task
+	gen   *generator  // generator whose body is evaluated, if any
+}
+
+// evaluation is the state shared by the main task, the tasks started with
+// spawn and the generators of a single evaluation.
+type evaluation struct {
+	steps int64 // evaluated nodes, updated atomically
+	sched *scheduler
+	done  chan struct{} // closed when the main task finishes
+
+	cancel     context.CancelFunc // stops the tasks left when the main one finishes
+	goroutines sync.WaitGroup     // of tasks and generator bodies
+
+	// Stdout and Stderr of the Evaluator, written by one task at a time
+	output         sync.Mutex
+	stdout, stderr io.Writer
+}
+
+func (ev *Evaluator) newEvaluation() *evaluation {
+	run := &evaluation{
+		sched: &scheduler{running: 1, blocked: make(map[*waiter]bool)},
+		done:  make(chan struct{}),
+	}
+
+	stdout, stderr := ev.Stdout, ev.Stderr
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	run.stdout = &lockedWriter{mu: &run.output, w: stdout}
+	run.stderr = &lockedWriter{mu: &run.output, w: stderr}
+
+	return run
+}
+
+// begin starts an evaluation that stops when ctx is done. It must be ended
+// with finish.
+func (ev *Evaluator) begin(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	ev.ctx = ctx
+	ev.run = ev.newEvaluation()
+	ev.run.cancel = cancel
+}
+
+// state returns the state of the evaluation in progress. It must be called
+// before ev is copied for another task, so that the copy shares it.
+func (ev *Evaluator) state() *evaluation {
+	if ev.run == nil {
+		ev.run = ev.newEvaluation()
+	}
+	return ev.run
+}
+
+// Limits bounds the resources a single evaluation may use.
+// Zero values mean no limit.
+type Limits struct {
+	MaxCallDepth int // nesting of Monkey function calls, per task
+	MaxSteps     int // evaluated nodes, counted over all tasks
+	MaxTasks     int // tasks started with spawn that have not finished
+}
+
+// Eval evaluates node with a new zero value Evaluator.
+// It is safe for concurrent use.
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	ev := &Evaluator{}
+	ev.begin(context.Background())
+	defer ev.finish()
+
+	return ev.Eval(node, env)
+}
+
+// Eval evaluates node in env and returns the resulting object.
+func (ev *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object {
+	steps := atomic.AddInt64(&ev.state().steps, 1)
+	if ev.Limits.MaxSteps > 0 && steps > int64(ev.Limits.MaxSteps) {
+		return newError("step limit exceeded: %d", ev.Limits.MaxSteps)
+	}
+
+	result := ev.eval(node, env)
+
+	// the innermost node that produced an error is where it happened
+	if err, ok := result.(*object.Error); ok && !err.Pos.IsValid() {
+		err.Pos = node.Pos()
+	}
+
+	return result
+}
+
+func (ev *Evaluator) eval(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 	// statement
 	case *ast.Program:
-		return evalProgram(node, env)
+		return ev.evalProgram(node, env)
 	case *ast.ExpressionStatement:
-		return Eval(node.Expression, env)
+		return ev.Eval(node.Expression, env)
 
 	case *ast.LetStatement:
-		val := Eval(node.Value, env)
+		val := ev.Eval(node.Value, env)
 		if isError(val) {
 			return val
 		}
 		env.Set(node.Name.Value, val)
 
 	case *ast.Identifier:
-		return evalIdentifier(node, env)
+		return ev.evalIdentifier(node, env)
 
 	case *ast.IfExpression:
-		return evalIfExpression(node, env)
+		return ev.evalIfExpression(node, env)
 	case *ast.BlockStatement:
-		return evalBlockStatement(node, env)
+		return ev.evalBlockStatement(node, env)
 
 	case *ast.PrefixExpression:
-		right := Eval(node.Right, env)
+		right := ev.Eval(node.Right, env)
 		if isError(right) {
 			return right
 		}
-		return evalPrefixExpression(node.Operator, right)
+		return ev.evalPrefixExpression(node.Operator, right)
 	case *ast.InfixExpression:
-		left := Eval(node.Left, env)
+		left := ev.Eval(node.Left, env)
 		if isError(left) {
 			return left
 		}
-		right := Eval(node.Right, env)
+		right := ev.Eval(node.Right, env)
 		if isError(right) {
 			return right
 		}
-		return evalInfixExpression(node.Operator, left, right)
+		return ev.evalInfixExpression(node.Operator, left, right)
 	case *ast.CallExpression:
-		function := Eval(node.Function, env)
+		if isCallTo(node, "quote") {
+			return ev.quote(node, env)
+		}
+		function := ev.Eval(node.Function, env)
 		if isError(function) {
 			return function
 		}
-		args := evalExpressions(node.Arguments, env)
+		args := ev.evalExpressions(node.Arguments, env)
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
-		return applyFunction(function, args)
+		return traceCall(ev.applyFunction(function, args), function, node)
 	case *ast.IndexExpression:
-		left := Eval(node.Left, env)
+		left := ev.Eval(node.Left, env)
 		if isError(left) {
 			return left
 		}
-		idx := Eval(node.Index, env)
+		idx := ev.Eval(node.Index, env)
 		if isError(idx) {
 			return idx
 		}
@@ -84,54 +209,85 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.FunctionLiteral:
 		params := node.Parameters
 		body := node.Body
-		return &object.Function{Parameters: params, Env: env, Body: body}
+		return &object.Function{Name: node.Name, Generator: node.Generator, Parameters: params, Env: env, Body: body}
+	case *ast.MacroLiteral:
+		return newError("macros must be defined with a top-level let")
 	case *ast.ArrayLiteral:
-		elems := evalExpressions(node.Elements, env)
+		elems := ev.evalExpressions(node.Elements, env)
 		if len(elems) == 1 && isError(elems[0]) {
 			return elems[0]
 		}
 		return &object.Array{Elements: elems}
 	case *ast.HashLiteral:
-		return evalHashLiteral(node, env)
+		return ev.evalHashLiteral(node, env)
 
 	case *ast.ReturnStatement:
-		val := Eval(node.ReturnValue, env)
+		val := ev.Eval(node.ReturnValue, env)
 		if isError(val) {
 			return val
 		}
 		return &object.ReturnValue{Value: val}
+
+	case *ast.ThrowStatement:
+		val := ev.Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		return throwValue(val)
+	case *ast.TryExpression:
+		return ev.evalTryExpression(node, env)
+
+	case *ast.YieldStatement:
+		val := ev.Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		if ev.gen == nil {
+			return newError("yield outside of generator")
+		}
+		if node.Delegate {
+			return ev.gen.yieldAll(callContext{ev}, val)
+		}
+		if err := ev.gen.yield(val); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func evalProgram(p *ast.Program, env *object.Environment) object.Object {
+func (ev *Evaluator) evalProgram(p *ast.Program, env *object.Environment) object.Object {
 	var result object.Object
 
 	for _, stmt := range p.Statements {
-		result = Eval(stmt, env)
+		if ev.ctx != nil {
+			if err := ev.ctx.Err(); err != nil {
+				return newError("evaluation stopped: %s", err)
+			}
+		}
+
+		result = ev.Eval(stmt, env)
 
 		switch result := result.(type) {
 		case *object.ReturnValue:
 			return result.Value
 		case *object.Error:
-			return result
+			if !result.Soft {
+				return result
+			}
 		}
 	}
 
 	return result
 }
 
-func evalBlockStatement(b *ast.BlockStatement, env *object.Environment) object.Object {
+func (ev *Evaluator) evalBlockStatement(b *ast.BlockStatement, env *object.Environment) object.Object {
 	var result object.Object
 
 	for _, stmt := range b.Statements {
-		result = Eval(stmt, env)
+		result = ev.Eval(stmt, env)
 
-		if result != nil {
-			rt := result.Type()
-			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
-				return result
-			}
+		if isError(result) {
+			return result
 		}
 
 		if result != nil && result.Type() == object.RETURN_VALUE_OBJ {
@@ -150,12 +306,12 @@ func nativeBoolToBooleanObject(i bool) *object.Boolean {
 	return FALSE
 }
 
-func evalPrefixExpression(operator string, right object.Object) object.Object {
+func (ev *Evaluator) evalPrefixExpression(operator string, right object.Object) object.Object {
 	switch operator {
 	case "!":
 		return evalBangOperatorExpression(right)
 	case "-":
-		return evalMinusPrefixOperatorExpression(right)
+		return ev.evalMinusPrefixOperatorExpression(right)
 	default:
 		return newError("unknown operator: %s%s", operator, right.Type())
 	}
@@ -174,19 +330,10 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 	}
 }
 
-func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
-		return newError("unknown operator: -%s", right.Type())
-	}
-
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
-}
-
-func evalInfixExpression(operator string, left, right object.Object) object.Object {
+func (ev *Evaluator) evalInfixExpression(operator string, left, right object.Object) object.Object {
 	switch {
-	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
-		return evalIntegerInfixExpresson(operator, left, right)
+	case isInteger(left) && isInteger(right):
+		return ev.evalIntegerInfixExpresson(operator, left, right)
 	case operator == "==":
 		return nativeBoolToBooleanObject(left == right)
 	case operator == "!=":
@@ -210,64 +357,41 @@ func evalStringInfixExpression(operator string, left, right object.Object) objec
 	return &object.String{Value: leftVal + rightVal}
 }
 
-func evalIntegerInfixExpresson(operator string, left, right object.Object) object.Object {
-	leftVal := left.(*object.Integer).Value
-	rightVal := right.(*object.Integer).Value
-
-	switch operator {
-	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
-	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
-	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
-	case "/":
-		return &object.Integer{Value: leftVal / rightVal}
-	case "<":
-		return nativeBoolToBooleanObject(leftVal < rightVal)
-	case ">":
-		return nativeBoolToBooleanObject(leftVal > rightVal)
-	case "==":
-		return nativeBoolToBooleanObject(leftVal == rightVal)
-	case "!=":
-		return nativeBoolToBooleanObject(leftVal != rightVal)
-	default:
-		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
-	}
-}
-
-func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
-	condition := Eval(ie.Condition, env)
+func (ev *Evaluator) evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
+	condition := ev.Eval(ie.Condition, env)
 	if isError(condition) {
 		return condition
 	}
 
 	if isTruth(condition) {
-		return Eval(ie.Consequence, env)
+		return ev.Eval(ie.Consequence, env)
 	} else if ie.Alternative != nil {
-		return Eval(ie.Alternative, env)
+		return ev.Eval(ie.Alternative, env)
 	} else {
 		return NULL
 	}
 }
 
-func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
+func (ev *Evaluator) evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
 	if val, ok := env.Get(node.Value); ok {
 		return val
 	}
 
-	if builtin, ok := builtins[node.Value]; ok {
+	if builtin, ok := ev.builtin(node.Value); ok {
+		if err := ev.checkBuiltin(node.Value); err != nil {
+			return err
+		}
 		return builtin
 	}
 
-	return newError("identifier not found: " + node.Value)
+	return newError("identifier not found: %s", node.Value)
 }
 
-func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
+func (ev *Evaluator) evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
 	var result []object.Object
 
 	for _, e := range exps {
-		evaluated := Eval(e, env)
+		evaluated := ev.Eval(e, env)
 		if isError(evaluated) {
 			return []object.Object{evaluated}
 		}
@@ -282,6 +406,14 @@ func evalIndexExpression(left, idx object.Object) object.Object {
 		return evalArrayIndexExpression(left, idx)
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, idx)
+	case left.Type() == object.ERROR_OBJ && idx.Type() == object.STRING_OBJ:
+		return evalErrorIndexExpression(left, idx)
+	case left.Type() == object.GO_VALUE_OBJ && idx.Type() == object.STRING_OBJ:
+		member, err := left.(*object.GoValue).Member(idx.(*object.String).Value)
+		if err != nil {
+			return newError("%s", err)
+		}
+		return member
 	default:
 		return newError("index operator not supported: %s", left.Type())
 	}
@@ -306,19 +438,53 @@ func evalHashIndexExpression(hash, idx object.Object) object.Object {
 		return newError("unusable as hash key: %s", idx.Type())
 	}
 
-	pair, ok := hashObj.Pairs[key.HashKey()]
+	value, ok := hashObj.Get(key)
 	if !ok {
 		return NULL
 	}
 
-	return pair.Value
+	return value
+}
+
+// evalErrorIndexExpression exposes the fields of a soft error like a hash.
+func evalErrorIndexExpression(err, idx object.Object) object.Object {
+	errObj := err.(*object.Error)
+
+	switch idx.(*object.String).Value {
+	case "message":
+		return &object.String{Value: errObj.Message}
+	case "value":
+		if errObj.Value == nil {
+			return NULL
+		}
+		return errObj.Value
+	case "cause":
+		if errObj.Cause == nil {
+			return NULL
+		}
+		return errObj.Cause
+	case "position":
+		return &object.String{Value: errObj.Pos.String()}
+	case "line":
+		return &object.Integer{Value: int64(errObj.Pos.Line)}
+	case "column":
+		return &object.Integer{Value: int64(errObj.Pos.Column)}
+	case "stack":
+		frames := make([]object.Object, len(errObj.Stack))
+		for i, f := range errObj.Stack {
+			frames[i] = &object.String{Value: f.String()}
+		}
+		return &object.Array{Elements: frames}
+	default:
+		return NULL
+	}
 }
 
-func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
-	pairs := make(map[object.HashKey]object.HashPair)
+func (ev *Evaluator) evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := &object.Hash{}
 
-	for keyNode, valueNode := range node.Pairs {
-		key := Eval(keyNode, env)
+	for _, pair := range node.Pairs {
+		key := ev.Eval(pair.Key, env)
 		if isError(key) {
 			return key
 		}
@@ -328,29 +494,141 @@ func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Obje
 			return newError("unusable as hash key: %s", key.Type())
 		}
 
-		value := Eval(valueNode, env)
+		value := ev.Eval(pair.Value, env)
 		if isError(value) {
 			return value
 		}
-		hashed := hashKey.HashKey()
-		pairs[hashed] = object.HashPair{Key: key, Value: value}
+		hash.Set(hashKey, value)
 	}
-	return &object.Hash{Pairs: pairs}
+	return hash
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+func (ev *Evaluator) evalTryExpression(te *ast.TryExpression, env *object.Environment) object.Object {
+	result := ev.Eval(te.Block, env)
+
+	// nothing more of the script runs once it has been stopped
+	if isError(result) && ev.stopped() {
+		return result
+	}
+
+	if err, ok := result.(*object.Error); ok && isError(err) && te.Catch != nil {
+		catchEnv := object.NewEnclosedEnvironment(env)
+		catchEnv.Set(te.Param.Value, catchError(err))
+		result = ev.Eval(te.Catch, catchEnv)
+	}
+
+	if te.Finally != nil {
+		// an error or return in finally replaces the outcome of the try
+		finally := ev.Eval(te.Finally, env)
+		if isError(finally) {
+			return finally
+		}
+		if _, ok := finally.(*object.ReturnValue); ok {
+			return finally
+		}
+	}
+
+	if result == nil {
+		return NULL
+	}
+
+	return result
+}
+
+// throwValue turns the operand of throw into an error that aborts evaluation.
+// Throwing a caught error rethrows it with its original position and stack.
+func throwValue(val object.Object) *object.Error {
+	if err, ok := val.(*object.Error); ok {
+		thrown := *err
+		thrown.Stack = append([]object.Frame(nil), err.Stack...)
+		thrown.Soft = false
+		return &thrown
+	}
+
+	return &object.Error{Message: val.Inspect(), Value: val}
+}
+
+// catchError returns a soft copy of err that can be bound to a variable.
+func catchError(err *object.Error) *object.Error {
+	caught := *err
+	caught.Stack = append([]object.Frame(nil), err.Stack...)
+	caught.Soft = true
+	return &caught
+}
+
+func (ev *Evaluator) applyFunction(fn object.Object, args []object.Object) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
+		if err := ev.checkCall(); err != nil {
+			return err
+		}
+
+		// extra arguments are ignored, missing ones cannot be bound
+		if len(args) < len(fn.Parameters) {
+			return newError("wrong number of arguments. got=%d, want=%d", len(args), len(fn.Parameters))
+		}
+
+		if fn.Generator {
+			return ev.newGenerator(fn, args)
+		}
+
+		ev.depth++
+		defer func() { ev.depth-- }()
+
 		extendedEnv := extendFunctionEnv(fn, args)
-		evaluated := Eval(fn.Body, extendedEnv)
+		evaluated := ev.Eval(fn.Body, extendedEnv)
 		return unwrapReturnValue(evaluated)
 	case *object.Builtin:
-		return fn.Fn(args...)
+		// the policy may have changed since the builtin was looked up
+		if b, ok := ev.builtin(fn.Name); ok && b == fn {
+			if err := ev.checkBuiltin(fn.Name); err != nil {
+				return err
+			}
+		}
+		return fn.Fn(callContext{ev}, args...)
 	default:
 		return newError("not a function %s", fn.Type())
 	}
 }
 
+// checkCall reports whether another function call is allowed.
+// stopped reports whether the evaluation, or the generator whose body ev
+// evaluates, has been stopped. The errors that stop them cannot be caught.
+func (ev *Evaluator) stopped() bool {
+	if ev.gen != nil && ev.gen.stopped() {
+		return true
+	}
+	return ev.ctx != nil && ev.ctx.Err() != nil
+}
+
+func (ev *Evaluator) checkCall() *object.Error {
+	if ev.ctx != nil {
+		if err := ev.ctx.Err(); err != nil {
+			return newError("evaluation stopped: %s", err)
+		}
+	}
+
+	if ev.Limits.MaxCallDepth > 0 && ev.depth >= ev.Limits.MaxCallDepth {
+		return newError("call depth limit exceeded: %d", ev.Limits.MaxCallDepth)
+	}
+
+	return nil
+}
+
+// traceCall records the call site of a Monkey function on an error leaving it.
+func traceCall(result, fn object.Object, call *ast.CallExpression) object.Object {
+	err, ok := result.(*object.Error)
+	if !ok || err.Soft {
+		return result
+	}
+
+	if f, ok := fn.(*object.Function); ok {
+		err.Stack = append(err.Stack, object.Frame{Function: f.Name, Pos: call.Pos()})
+	}
+
+	return err
+}
+
 func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
 	env := object.NewEnclosedEnvironment(fn.Env)
 
@@ -386,9 +664,10 @@ func newError(format string, a ...interface{}) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
 
+// isError reports whether obj is an error that aborts evaluation.
 func isError(obj object.Object) bool {
-	if obj != nil {
-		return obj.Type() == object.ERROR_OBJ
+	if err, ok := obj.(*object.Error); ok {
+		return !err.Soft
 	}
 
 	return false
@@ -1,8 +1,13 @@
 package evaluator
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
 	"testing"
 
+	"github.com/smith-30/go-monkey/ast"
 	"github.com/smith-30/go-monkey/lexer"
 	"github.com/smith-30/go-monkey/object"
 	"github.com/smith-30/go-monkey/parser"
@@ -330,7 +335,7 @@ func TestFunctionObject(t *testing.T) {
 		t.Fatalf("parameter is not 'x, got=%q", fn.Parameters[0])
 	}
 
-	expBody := "(x + 2)"
+	expBody := "{ (x + 2) }"
 	if fn.Body.String() != expBody {
 		t.Fatalf("Body is not %q. got=%q", expBody, fn.Body.String())
 	}
@@ -366,6 +371,11 @@ func TestFunctionApplication(t *testing.T) {
 			input: "fn(x) { x; }(5)",
 			exp:   5,
 		},
+		{
+			name:  "extra arguments are ignored",
+			input: "let identity = fn(x) { x; }; identity(5, 6);",
+			exp:   5,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -591,7 +601,7 @@ func TestHashLiteralList(t *testing.T) {
 	tests := []struct {
 		name  string
 		input string
-		exp   map[object.HashKey]int64
+		exp   []hashPairExp // in insertion order
 	}{
 		{
 			input: `let two = "two";
@@ -603,13 +613,21 @@ func TestHashLiteralList(t *testing.T) {
 				true: 5,
 				false: 6
 			}`,
-			exp: map[object.HashKey]int64{
-				(&object.String{Value: "one"}).HashKey():   1,
-				(&object.String{Value: "two"}).HashKey():   2,
-				(&object.String{Value: "three"}).HashKey(): 3,
-				(&object.Integer{Value: 4}).HashKey():      4,
-				TRUE.HashKey():                             5,
-				FALSE.HashKey():                            6,
+			exp: []hashPairExp{
+				{&object.String{Value: "one"}, 1},
+				{&object.String{Value: "two"}, 2},
+				{&object.String{Value: "three"}, 3},
+				{&object.Integer{Value: 4}, 4},
+				{TRUE, 5},
+				{FALSE, 6},
+			},
+		},
+		{
+			name:  "duplicate key keeps its first place",
+			input: `{"b": 1, "a": 2, "b": 3}`,
+			exp: []hashPairExp{
+				{&object.String{Value: "b"}, 3},
+				{&object.String{Value: "a"}, 2},
 			},
 		},
 	}
@@ -621,22 +639,34 @@ func TestHashLiteralList(t *testing.T) {
 				t.Fatalf("object is not Hash. got=%T (%+v)", evaluated, evaluated)
 			}
 
-			if len(result.Pairs) != len(tt.exp) {
-				t.Fatalf("Hash has wrong num of pairs. got=%d", len(result.Pairs))
+			if result.Len() != len(tt.exp) {
+				t.Fatalf("Hash has wrong num of pairs. got=%d", result.Len())
 			}
 
-			for expKey, expVal := range tt.exp {
-				pair, ok := result.Pairs[expKey]
+			for i, pair := range result.Pairs() {
+				exp := tt.exp[i]
+				if pair.Key.Inspect() != exp.key.Inspect() {
+					t.Errorf("pair %d: want key %s, but %s", i, exp.key.Inspect(), pair.Key.Inspect())
+				}
+				testIntegerObject(t, pair.Value, exp.value)
+
+				value, ok := result.Get(exp.key)
 				if !ok {
-					t.Errorf("no pair for given key in Pairs")
+					t.Errorf("no pair for key %s", exp.key.Inspect())
+					continue
 				}
-				testIntegerObject(t, pair.Value, expVal)
+				testIntegerObject(t, value, exp.value)
 			}
 
 		})
 	}
 }
 
+type hashPairExp struct {
+	key   object.Hashable
+	value int64
+}
+
 func TestHashIndexExpressions(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -693,3 +723,480 @@ func testEval(i string) object.Object {
 
 	return Eval(program, env)
 }
+
+func TestErrorPosition(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+	}{
+		{
+			name: "top level",
+			input: `let a = 1;
+let b = a + "x";`,
+			exp: "ERROR: type mismatch: INTEGER + STRING at 2:11",
+		},
+		{
+			name: "nested calls",
+			input: `let add = fn(x, y) {
+  x + y
+};
+let outer = fn() {
+  add(1, true)
+};
+outer();`,
+			exp: "ERROR: type mismatch: INTEGER + BOOLEAN at 2:5\n" +
+				"\tin add, called at 5:6\n" +
+				"\tin outer, called at 7:6",
+		},
+		{
+			name:  "anonymous function",
+			input: `fn() { missing }()`,
+			exp:   "ERROR: identifier not found: missing at 1:8\n\tin <anonymous>, called at 1:17",
+		},
+		{
+			name:  "builtin",
+			input: `len(1)`,
+			exp:   "ERROR: argument to `len` not supported, got INTEGER at 1:4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated := testEval(tt.input)
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Fatalf("no error object returned. got=%T (%#v)", evaluated, evaluated)
+			}
+
+			if errObj.Inspect() != tt.exp {
+				t.Errorf("want %q, but %q", tt.exp, errObj.Inspect())
+			}
+		})
+	}
+}
+
+func TestIntegerDivisionByZero(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "literal", input: "1 / 0"},
+		{name: "variable", input: "let zero = 5 - 5; 10 / zero"},
+		{name: "in function", input: "let f = fn(x) { 100 / x }; f(0) + 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated := testEval(tt.input)
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Fatalf("no error object returned. got=%T (%#v)", evaluated, evaluated)
+			}
+
+			if errObj.Message != "division by zero" {
+				t.Errorf("want %q, but %q", "division by zero", errObj.Message)
+			}
+		})
+	}
+}
+
+func TestCheckedArithmetic(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		promoted string
+		exp      string
+	}{
+		{"add", "9223372036854775807 + 1", "9223372036854775808", "integer overflow: 9223372036854775807 + 1"},
+		{"sub", "-9223372036854775807 - 2", "-9223372036854775809", "integer overflow: -9223372036854775807 - 2"},
+		{"mul", "4611686018427387904 * 2", "9223372036854775808", "integer overflow: 4611686018427387904 * 2"},
+		{"div", "(-9223372036854775807 - 1) / -1", "9223372036854775808", "integer overflow: -9223372036854775808 / -1"},
+		{"neg", "-(-9223372036854775807 - 1)", "9223372036854775808", "integer overflow: -(-9223372036854775808)"},
+		{"in range", "9223372036854775806 + 1", "9223372036854775807", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := parser.New(lexer.New(tt.input)).ParseProgram()
+
+			promoted := (&Evaluator{}).Eval(program, object.NewEnvironment())
+			if promoted.Inspect() != tt.promoted {
+				t.Errorf("want %s, but %s", tt.promoted, promoted.Inspect())
+			}
+
+			checked := (&Evaluator{CheckedArithmetic: true}).Eval(program, object.NewEnvironment())
+			if tt.exp == "" {
+				testIntegerObject(t, checked, math.MaxInt64)
+				return
+			}
+
+			errObj, ok := checked.(*object.Error)
+			if !ok {
+				t.Fatalf("no error object returned. got=%T (%#v)", checked, checked)
+			}
+			if errObj.Message != tt.exp {
+				t.Errorf("want %q, but %q", tt.exp, errObj.Message)
+			}
+		})
+	}
+}
+
+func TestBigIntPromotion(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   interface{}
+	}{
+		{"promote add", "9223372036854775807 + 9223372036854775807", "18446744073709551614"},
+		{"promote mul", "3037000500 * 3037000500 * 10", "92233720370002500000"},
+		{"demote", "9223372036854775807 + 1 - 1", int64(9223372036854775807)},
+		{"demote div", "(9223372036854775807 * 4) / 4", int64(9223372036854775807)},
+		{"mixed sub", "1 - (9223372036854775807 * 2)", "-18446744073709551613"},
+		{
+			"factorial",
+			`let fact = fn(n) { if (n < 2) { 1 } else { n * fact(n - 1) } }; fact(25)`,
+			"15511210043330985984000000",
+		},
+		{"negate", "-(9223372036854775807 * 2)", "-18446744073709551614"},
+		{"less", "9223372036854775807 < 9223372036854775807 + 1", true},
+		{"greater", "9223372036854775807 * 3 > 9223372036854775807 * 2", true},
+		{"equal", "9223372036854775807 * 2 == 9223372036854775807 + 9223372036854775807", true},
+		{"not equal", "9223372036854775807 * 2 != 1", true},
+		{"division by zero", "(9223372036854775807 * 2) / 0", errors.New("division by zero")},
+		{"unknown operator", `-"a"`, errors.New("unknown operator: -STRING")},
+		{"hash key", `let k = 9223372036854775807 * 2; {k: 1}[9223372036854775807 + 9223372036854775807]`, int64(1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated := testEval(tt.input)
+
+			switch exp := tt.exp.(type) {
+			case string:
+				bi, ok := evaluated.(*object.BigInt)
+				if !ok {
+					t.Fatalf("object is not BigInt. got=%T (%+v)", evaluated, evaluated)
+				}
+				if bi.Inspect() != exp {
+					t.Errorf("want %s, but %s", exp, bi.Inspect())
+				}
+			case int64:
+				testIntegerObject(t, evaluated, exp)
+			case bool:
+				testBooleanObject(t, evaluated, exp)
+			case error:
+				errObj, ok := evaluated.(*object.Error)
+				if !ok {
+					t.Fatalf("no error object returned. got=%T (%#v)", evaluated, evaluated)
+				}
+				if errObj.Message != exp.Error() {
+					t.Errorf("want %q, but %q", exp.Error(), errObj.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestTryExpression(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   interface{}
+	}{
+		{"no error", `try { 1 } catch (e) { 2 }`, 1},
+		{"thrown value", `try { throw 5; 1 } catch (e) { e["value"] }`, 5},
+		{"thrown message", `try { throw "boom" } catch (e) { e["message"] }`, "boom"},
+		{"runtime error", `try { 1 + true } catch (e) { e["message"] }`, "type mismatch: INTEGER + BOOLEAN"},
+		{"runtime error value", `try { 1 / 0 } catch (e) { e["value"] }`, nil},
+		{"division by zero", `try { 1 / 0 } catch (e) { e["message"] }`, "division by zero"},
+		{"position", "let x = 1;\ntry {\n  x + true\n} catch (e) { e[\"position\"] }", "3:5"},
+		{"line", "try {\n\n  throw 1\n} catch (e) { e[\"line\"] }", 3},
+		{
+			"from function",
+			`let f = fn() { throw "inner" }; try { f() } catch (e) { e["message"] + "!" }`,
+			"inner!",
+		},
+		{
+			"stack",
+			`let f = fn() { throw "inner" }; try { f() } catch (e) { len(e["stack"]) }`,
+			1,
+		},
+		{
+			"finally runs",
+			`let log = []; let r = try { 1 } finally { let log = push(log, 2); }; log[0]`,
+			2,
+		},
+		{
+			"finally after catch",
+			`let f = fn() { let out = try { throw 1 } catch (e) { 10 } finally { 20 }; out }; f()`,
+			10,
+		},
+		{
+			"return in try",
+			`let f = fn() { try { return 1; } finally { 2 }; 3 }; f()`,
+			1,
+		},
+		{
+			"return in finally",
+			`let f = fn() { try { return 1; } finally { return 2; }; 3 }; f()`,
+			2,
+		},
+		{
+			"rethrow",
+			`try { try { throw "a" } catch (e) { throw e } } catch (e) { e["message"] }`,
+			"a",
+		},
+		{
+			"catch scope",
+			`let e = 1; try { throw 2 } catch (e) { e }; e`,
+			1,
+		},
+		{"uncaught", `throw "boom"; 1`, errors.New("boom")},
+		{"uncaught in try finally", `try { throw "boom" } finally { 1 }`, errors.New("boom")},
+		{"error in finally", `try { 1 } finally { throw "late" }`, errors.New("late")},
+		{"error in catch", `try { throw 1 } catch (e) { e["value"] + true }`, errors.New("type mismatch: INTEGER + BOOLEAN")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated := testEval(tt.input)
+
+			switch exp := tt.exp.(type) {
+			case int:
+				testIntegerObject(t, evaluated, int64(exp))
+			case string:
+				str, ok := evaluated.(*object.String)
+				if !ok {
+					t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+				}
+				if str.Value != exp {
+					t.Errorf("want %q, but %q", exp, str.Value)
+				}
+			case error:
+				errObj, ok := evaluated.(*object.Error)
+				if !ok {
+					t.Fatalf("no error object returned. got=%T (%#v)", evaluated, evaluated)
+				}
+				if errObj.Message != exp.Error() {
+					t.Errorf("want %q, but %q", exp.Error(), errObj.Message)
+				}
+			default:
+				testNullObject(t, evaluated)
+			}
+		})
+	}
+}
+
+func TestErrorValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   interface{}
+	}{
+		{"is_error", `is_error(error("bad"))`, true},
+		{"is_error false", `is_error(1)`, false},
+		{"does not abort", `let e = error("bad"); 1`, 1},
+		{
+			"soft failure",
+			`let div = fn(a, b) { if (b == 0) { return error("division by zero"); }; a / b };
+			let r = div(1, 0);
+			if (is_error(r)) { r["message"] } else { r }`,
+			"division by zero",
+		},
+		{"message", `error("bad")["message"]`, "bad"},
+		{"unwrap", `unwrap(error("outer", error("inner")))["message"]`, "inner"},
+		{"unwrap without cause", `unwrap(error("bad"))`, nil},
+		{"cause", `error("outer", error("inner"))["cause"]["message"]`, "inner"},
+		{"caught is error", `try { throw 1 } catch (e) { is_error(e) }`, true},
+		{"wrap caught", `try { 1 / 0 } catch (e) { unwrap(error("calc", e))["message"] }`, "division by zero"},
+		{"throw soft", `let e = error("bad"); try { throw e } catch (c) { c["message"] }`, "bad"},
+		{"uncaught throw", `throw error("bad"); 1`, errors.New("bad")},
+		{"wrong argument", `error(1)`, errors.New("argument to `error` must be String, got=INTEGER")},
+		{"wrong cause", `error("a", 1)`, errors.New("cause given to `error` must be Error, got=INTEGER")},
+		{"wrong unwrap", `unwrap(1)`, errors.New("argument to `unwrap` must be Error, got=INTEGER")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated := testEval(tt.input)
+
+			switch exp := tt.exp.(type) {
+			case int:
+				testIntegerObject(t, evaluated, int64(exp))
+			case bool:
+				testBooleanObject(t, evaluated, exp)
+			case string:
+				str, ok := evaluated.(*object.String)
+				if !ok {
+					t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+				}
+				if str.Value != exp {
+					t.Errorf("want %q, but %q", exp, str.Value)
+				}
+			case error:
+				errObj, ok := evaluated.(*object.Error)
+				if !ok {
+					t.Fatalf("no error object returned. got=%T (%#v)", evaluated, evaluated)
+				}
+				if errObj.Soft {
+					t.Errorf("error is soft")
+				}
+				if errObj.Message != exp.Error() {
+					t.Errorf("want %q, but %q", exp.Error(), errObj.Message)
+				}
+			default:
+				testNullObject(t, evaluated)
+			}
+		})
+	}
+}
+
+func TestErrorInspectCause(t *testing.T) {
+	evaluated := testEval(`error("outer", error("inner"))`)
+
+	exp := "ERROR: outer at 1:6\ncaused by: inner at 1:21"
+	if evaluated.Inspect() != exp {
+		t.Errorf("want %q, but %q", exp, evaluated.Inspect())
+	}
+}
+
+func TestCollectionBuiltins(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+	}{
+		{"map", `map([1, 2, 3], fn(x) { x * 2 })`, "[2, 4, 6]"},
+		{"map builtin", `map([[1], [2, 3]], len)`, "[1, 2]"},
+		{"map empty", `map([], fn(x) { x })`, "[]"},
+		{"map hash", `map({"a": 1}, fn(k, v) { k + ":" })`, `["a:"]`},
+		{"filter", `filter([1, 2, 3, 4], fn(x) { x > 2 })`, "[3, 4]"},
+		{"filter hash", `len(map(filter({"a": 1, "b": 2}, fn(k, v) { v > 1 }), fn(k, v) { k }))`, "1"},
+		{"reduce", `reduce([1, 2, 3, 4], 0, fn(acc, x) { acc + x })`, "10"},
+		{"reduce empty", `reduce([], 5, fn(acc, x) { acc + x })`, "5"},
+		{"find", `find([1, 2, 3], fn(x) { x > 1 })`, "2"},
+		{"find none", `find([1, 2, 3], fn(x) { x > 5 })`, "null"},
+		{"any", `any([1, 2, 3], fn(x) { x == 2 })`, "true"},
+		{"any false", `any([], fn(x) { true })`, "false"},
+		{"all", `all([1, 2, 3], fn(x) { x > 0 })`, "true"},
+		{"all false", `all([1, 2, 3], fn(x) { x > 1 })`, "false"},
+		{"all hash", `all({"a": 1, "b": 2}, fn(k, v) { v > 0 })`, "true"},
+		{"each", `let f = fn(x) { puts(x) }; each([], f)`, "null"},
+		{"sort", `sort([3, 1, 2])`, "[1, 2, 3]"},
+		{"sort strings", `sort(["b", "c", "a"])`, `["a", "b", "c"]`},
+		{"sort big", `sort([9223372036854775807 * 2, -1, 9223372036854775807])`, "[-1, 9223372036854775807, 18446744073709551614]"},
+		{"sort comparator", `sort([1, 3, 2], fn(a, b) { a > b })`, "[3, 2, 1]"},
+		{"sort does not modify", `let a = [2, 1]; sort(a); a`, "[2, 1]"},
+		{"sort_by", `sort_by(["ccc", "a", "bb"], len)`, `["a", "bb", "ccc"]`},
+		{"sort_by stable", `sort_by([[1, "b"], [0, "c"], [1, "a"]], first)`, `[[0, "c"], [1, "b"], [1, "a"]]`},
+		{"large input", `len(map(map(range_list, fn(x) { x + 1 }), fn(x) { x }))`, "10000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := parser.New(l)
+			program := p.ParseProgram()
+
+			env := object.NewEnvironment()
+			elems := make([]object.Object, 10000)
+			for i := range elems {
+				elems[i] = &object.Integer{Value: int64(i)}
+			}
+			env.Set("range_list", &object.Array{Elements: elems})
+
+			evaluated := Eval(program, env)
+			if evaluated.Inspect() != tt.exp {
+				t.Errorf("want %q, but %q", tt.exp, evaluated.Inspect())
+			}
+		})
+	}
+}
+
+func TestEachCallsInOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+	}{
+		{name: "array", input: `each([3, 1, 2], fn(x) { puts(x) })`, exp: "3\n1\n2\n"},
+		{name: "hash", input: `each({"b": 1, "a": 2}, fn(k, v) { puts(k, v) })`, exp: "b\n1\na\n2\n"},
+		{name: "builtin", input: `each(["x", "y"], puts)`, exp: "x\ny\n"},
+		{name: "empty", input: `each([], fn(x) { puts(x) })`, exp: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			interp := NewInterpreter()
+			interp.Stdout = &out
+
+			result, err := interp.Run(context.Background(), tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			testNullObject(t, result)
+
+			if out.String() != tt.exp {
+				t.Errorf("want %q, but %q", tt.exp, out.String())
+			}
+		})
+	}
+}
+
+func TestCollectionBuiltinErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+	}{
+		{"map arity", `map([1])`, "wrong number of arguments. got=1, want=2"},
+		{"map collection", `map(1, fn(x) { x })`, "first argument to `map` must be Array or Hash, got=INTEGER"},
+		{"map function", `map([1], 1)`, "second argument to `map` must be Function, got=INTEGER"},
+		{"callback error", `map([1, 0], fn(x) { 1 / x })`, "division by zero"},
+		{"callback arity", `map([1], fn(x, y) { x })`, "wrong number of arguments. got=1, want=2"},
+		{"reduce array", `reduce({}, 0, fn(a, x) { a })`, "first argument to `reduce` must be Array or Iterator, got=HASH"},
+		{"reduce function", `reduce([], 0, 0)`, "third argument to `reduce` must be Function, got=INTEGER"},
+		{"find array", `find("a", fn(x) { x })`, "first argument to `find` must be Array or Iterator, got=STRING"},
+		{"sort arity", `sort()`, "wrong number of arguments. got=0, want=1 or 2"},
+		{"sort mixed", `sort([1, "a"])`, "cannot compare INTEGER and STRING"},
+		{"sort comparator error", `sort([1, 2], fn(a, b) { a + true })`, "type mismatch: INTEGER + BOOLEAN"},
+		{"sort_by key", `sort_by([1, 2], fn(x) { true })`, "cannot compare BOOLEAN and BOOLEAN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluated := testEval(tt.input)
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Fatalf("no error object returned. got=%T (%#v)", evaluated, evaluated)
+			}
+
+			if errObj.Message != tt.exp {
+				t.Errorf("want %q, but %q", tt.exp, errObj.Message)
+			}
+		})
+	}
+}
+
+func TestEvalDecodedProgram(t *testing.T) {
+	tests := []string{
+		`let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } }; fib(10)`,
+		`let h = {"a": [1, 2], true: "yes"}; [h["a"][1], h[true], len("four")]`,
+		`let g = fn() { yield 1; yield* [2, 3]; }; collect(g())`,
+		`try { throw "boom"; } catch (e) { e }`,
+		`let f = fn(x) { x + missing }; f(1)`,
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			program := parser.New(lexer.New(input)).ParseProgram()
+
+			data, err := ast.MarshalJSON(program)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			decoded, err := ast.UnmarshalProgram(data)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			exp := Eval(program, object.NewEnvironment()).Inspect()
+			if got := Eval(decoded, object.NewEnvironment()).Inspect(); got != exp {
+				t.Errorf("want %q, but %q", exp, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package evaluator
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/smith-30/go-monkey/object"
+)
+
+// generator evaluates the body of a generator function on a goroutine of
+// its own, which runs only while the consumer waits for the next value.
+type generator struct {
+	mu      sync.Mutex
+	started bool
+	done    bool
+
+	values   chan object.Object // yielded values, closed when the body ends
+	resume   chan struct{}      // asks the body for the next value
+	stop     chan struct{}      // closed when the iterator is no longer used
+	finished chan struct{}      // closed when the evaluation finishes
+	ctx      context.Context
+
+	goroutines *sync.WaitGroup // of the evaluation, which waits for the body
+}
+
+// newGenerator returns an iterator over the values fn yields when called
+// with args. The body does not run before the first value is asked for.
+// The generator cannot be resumed once the evaluation that created it has
+// finished.
+func (ev *Evaluator) newGenerator(fn *object.Function, args []object.Object) *object.Iterator {
+	g := &generator{
+		values:   make(chan object.Object),
+		resume:   make(chan struct{}),
+		stop:     make(chan struct{}),
+		finished: ev.state().done,
+		ctx:      ev.ctx,
+
+		goroutines: &ev.state().goroutines,
+	}
+	if g.ctx == nil {
+		g.ctx = context.Background()
+	}
+
+	// the body shares the step budget of the evaluation
+	child := *ev
+	child.gen = g
+	env := extendFunctionEnv(fn, args)
+
+	it := &object.Iterator{Next: func(object.CallContext) (object.Object, bool) {
+		return g.next(func() {
+			g.goroutines.Add(1)
+			go g.run(&child, fn, env)
+		})
+	}}
+
+	// finish stops the generators of an evaluation; this only releases
+	// those dropped earlier, or those of an Evaluator that is never finished
+	runtime.SetFinalizer(it, func(*object.Iterator) { close(g.stop) })
+
+	return it
+}
+
+func (g *generator) run(ev *Evaluator, fn *object.Function, env *object.Environment) {
+	defer g.goroutines.Done()
+	defer close(g.values)
+
+	select {
+	case <-g.resume:
+	case <-g.stop:
+		return
+	case <-g.finished:
+		return
+	}
+
+	ev.depth++
+	result := ev.Eval(fn.Body, env)
+
+	// a stopped generator ends without the error it was stopped with
+	if !isError(result) || g.stopped() {
+		return
+	}
+
+	select {
+	case g.values <- result:
+	case <-g.stop:
+	case <-g.finished:
+	}
+}
+
+// stopped reports whether the generator or its evaluation was stopped.
+func (g *generator) stopped() bool {
+	select {
+	case <-g.stop:
+		return true
+	case <-g.finished:
+		return true
+	default:
+		return false
+	}
+}
+
+// next resumes the body until it yields or ends.
+func (g *generator) next(start func()) (object.Object, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.done {
+		return nil, false
+	}
+	// the body ran on behalf of an evaluation that is gone, like a previous
+	// line of the REPL
+	select {
+	case <-g.finished:
+		return newError("generator's evaluation has finished"), true
+	default:
+	}
+	if !g.started {
+		g.started = true
+		start()
+	}
+
+	var v object.Object
+	var ok bool
+
+	// the body may have ended with an error without being resumed
+	select {
+	case g.resume <- struct{}{}:
+		v, ok = <-g.values
+	case v, ok = <-g.values:
+	}
+
+	if !ok || isError(v) {
+		g.done = true
+	}
+	return v, ok
+}
+
+// yieldAll yields every value of an array or iterator. It returns nil
+// unless an error ends the iteration.
+func (g *generator) yieldAll(ctx object.CallContext, val object.Object) object.Object {
+	it, err := iterate("operand of yield*", val)
+	if err != nil {
+		return err
+	}
+
+	for v, ok := it.Next(ctx); ok; v, ok = it.Next(ctx) {
+		if isError(v) {
+			return v
+		}
+		if err := g.yield(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// yield hands val to the consumer and waits until it asks for the next
+// value.
+func (g *generator) yield(val object.Object) *object.Error {
+	select {
+	case g.values <- val:
+	case <-g.stop:
+		return newError("generator stopped")
+	case <-g.finished:
+		return newError("generator stopped")
+	case <-g.ctx.Done():
+		return newError("evaluation stopped: %s", g.ctx.Err())
+	}
+
+	select {
+	case <-g.resume:
+		return nil
+	case <-g.stop:
+		return newError("generator stopped")
+	case <-g.finished:
+		return newError("generator stopped")
+	case <-g.ctx.Done():
+		return newError("evaluation stopped: %s", g.ctx.Err())
+	}
+}
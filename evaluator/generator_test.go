@@ -0,0 +1,155 @@
+package evaluator
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerators(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+		err   string
+	}{
+		{name: "collect", input: `let g = fn(n) { yield n; yield n + 1; yield n + 2; }; collect(g(1))`, exp: "[1, 2, 3]"},
+		{name: "next", input: `let g = fn() { yield "a"; yield "b"; }; let it = g(); [next(it), next(it), next(it)]`, exp: `["a", "b", null]`},
+		{name: "lazy", input: `let log = channel(10); let g = fn() { send(log, 1); yield 1; send(log, 2); yield 2; }; let it = g(); next(it); close(log); collect(take(zip(range(5), [receive(log), receive(log)]), 5))`, exp: "[[0, 1], [1, null]]"},
+		{name: "infinite", input: `let nat = fn(n) { yield n; yield* nat(n + 1); }; collect(take(nat(0), 3))`, exp: "[0, 1, 2]"},
+		{name: "recursive", input: `let count = fn(n) { if (n > 0) { yield n; yield* count(n - 1); } }; collect(count(3))`, exp: "[3, 2, 1]"},
+		{name: "delegate array", input: `let g = fn(xs) { yield 0; yield* xs; }; collect(g([1, 2]))`, exp: "[0, 1, 2]"},
+		{name: "delegate type", input: `let g = fn() { yield* 1; }; collect(g())`, err: "operand of yield* must be Array or Iterator, got=INTEGER at 1:16"},
+		{name: "return ends", input: `let g = fn() { yield 1; return 5; yield 2; }; collect(g())`, exp: "[1]"},
+		{name: "empty", input: `let g = fn(x) { if (x) { yield 1; } }; collect(g(false))`, exp: "[]"},
+		{name: "error", input: `let g = fn() { yield 1; yield 1 / 0; }; collect(g())`, err: "division by zero at 1:33"},
+		{name: "error ends", input: `let g = fn() { yield 1 / 0; }; let it = g(); try { next(it) } catch (e) { next(it) }`, exp: "null"},
+		{name: "closure", input: `let counter = fn(from) { fn(n) { yield from; yield from + n; } }; collect(counter(10)(5))`, exp: "[10, 15]"},
+		{name: "arity", input: `let g = fn(a) { yield a; }; g()`, err: "wrong number of arguments. got=0, want=1 at 1:30\n\tin g, called at 1:30"},
+		{name: "inspect", input: `let g = fn() { yield 1; }; g()`, exp: "iterator"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRun(t, NewInterpreter(), tt.input, tt.exp, tt.err)
+		})
+	}
+}
+
+func TestIteratorBuiltins(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+		err   string
+	}{
+		{name: "range", input: `collect(range(3))`, exp: "[0, 1, 2]"},
+		{name: "range start", input: `collect(range(2, 5))`, exp: "[2, 3, 4]"},
+		{name: "range step", input: `collect(range(10, 0, -3))`, exp: "[10, 7, 4, 1]"},
+		{name: "range empty", input: `collect(range(5, 2))`, exp: "[]"},
+		{name: "range overflow", input: `collect(range(9223372036854775806, 9223372036854775807, 5))`, exp: "[9223372036854775806]"},
+		{name: "range step zero", input: `range(1, 2, 0)`, err: "step of `range` must not be 0 at 1:6"},
+		{name: "range type", input: `range("a")`, err: "arguments to `range` must be Integer, got=STRING at 1:6"},
+		{name: "take", input: `collect(take(range(1000000000000), 2))`, exp: "[0, 1]"},
+		{name: "take array", input: `collect(take([1, 2, 3], 5))`, exp: "[1, 2, 3]"},
+		{name: "take count", input: `take([], -1)`, err: "second argument to `take` must be a non-negative Integer, got=-1 at 1:5"},
+		{name: "zip", input: `collect(zip([1, 2, 3], range(10), ["a", "b"]))`, exp: `[[1, 0, "a"], [2, 1, "b"]]`},
+		{name: "enumerate", input: `collect(enumerate(["a", "b"]))`, exp: `[[0, "a"], [1, "b"]]`},
+		{name: "next array", input: `next([1])`, err: "argument to `next` must be Iterator, got=ARRAY at 1:5"},
+		{name: "collect type", input: `collect(1)`, err: "argument to `collect` must be Array or Iterator, got=INTEGER at 1:8"},
+		{name: "map lazy", input: `collect(take(map(range(1000000000000), fn(x) { x * x }), 4))`, exp: "[0, 1, 4, 9]"},
+		{name: "filter lazy", input: `collect(take(filter(range(1000000000000), fn(x) { x / 2 * 2 == x }), 3))`, exp: "[0, 2, 4]"},
+		{name: "map error", input: `collect(map(range(3), fn(x) { 1 / x }))`, err: "division by zero at 1:33"},
+		{name: "reduce", input: `reduce(range(101), 0, fn(a, x) { a + x })`, exp: "5050"},
+		{name: "find", input: `find(range(1000000000000), fn(x) { x > 41 })`, exp: "42"},
+		{name: "each", input: `let ch = channel(3); each(range(3), fn(x) { send(ch, x) }); [receive(ch), receive(ch), receive(ch)]`, exp: "[0, 1, 2]"},
+		{name: "shared iterator", input: `let it = range(4); next(it); collect(it)`, exp: "[1, 2, 3]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRun(t, NewInterpreter(), tt.input, tt.exp, tt.err)
+		})
+	}
+}
+
+func TestIteratorOnTask(t *testing.T) {
+	// the callback of a lazy map runs on the task that consumes it, while
+	// the task that created the iterator keeps calling functions; run with
+	// -race to check that they do not share a call stack
+	input := `
+let it = map(range(2000), fn(x) { x + 1 });
+let t = spawn(collect, it);
+let sum = reduce(range(2000), 0, fn(a, x) { a + x });
+[sum, len(await(t))]`
+
+	testRun(t, NewInterpreter(), input, "[1999000, 2000]", "")
+}
+
+func TestGeneratorCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// the generator keeps yielding until the evaluation is stopped
+	_, err := NewInterpreter().Run(ctx, `
+let nat = fn(n) { yield n; yield* nat(n + 1); };
+reduce(nat(0), 0, fn(a, x) { select([channel()], 1); a + x })`)
+
+	if err == nil || !strings.HasPrefix(err.Error(), "evaluation stopped: context deadline exceeded") {
+		t.Errorf("want cancellation error, but %v", err)
+	}
+}
+
+func TestGeneratorFinish(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	// the suspended generators stay reachable from the environment, so
+	// only the end of each run stops them
+	interp := NewInterpreter()
+	for i := 0; i < 10; i++ {
+		if _, err := interp.Run(context.Background(), `let g = fn() { yield 1; yield 2; }; let it = g(); next(it)`); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("want at most %d goroutines, but %d", before, n)
+	}
+
+	// a generator cannot outlive the evaluation that created it, started or
+	// not, and says so instead of looking exhausted
+	testRun(t, interp, `next(it)`, "", "generator's evaluation has finished at 1:5")
+	if _, err := interp.Run(context.Background(), `let g = fn() { yield 1; }; let it = g();`); err != nil {
+		t.Fatal(err)
+	}
+	testRun(t, interp, `collect(it)`, "", "generator's evaluation has finished at 1:8")
+
+	// one that was used up stays so
+	if _, err := interp.Run(context.Background(), `let it = g(); collect(it)`); err != nil {
+		t.Fatal(err)
+	}
+	testRun(t, interp, `next(it)`, "null", "")
+}
+
+func TestGeneratorStopUncatchable(t *testing.T) {
+	var out bytes.Buffer
+	interp := NewInterpreter()
+	interp.Stdout = &out
+
+	// the suspended body is stopped when the run ends, without running the
+	// catch or finally blocks around the yield
+	testRun(t, interp, `
+let g = fn() { try { yield 1; yield 2; } catch (e) { puts("after: " + e["message"]) } finally { puts("finally") } };
+let it = g();
+next(it)`, "1", "")
+
+	time.Sleep(20 * time.Millisecond)
+	if out.Len() != 0 {
+		t.Errorf("want no output, but %q", out.String())
+	}
+}
@@ -0,0 +1,130 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/smith-30/go-monkey/object"
+)
+
+// Integers are *object.Integer while they fit in int64 and are promoted to
+// *object.BigInt when an operation overflows. A BigInt result that fits in
+// int64 again is demoted, so the same value always has the same type.
+
+func isInteger(obj object.Object) bool {
+	t := obj.Type()
+	return t == object.INTEGER_OBJ || t == object.BIGINT_OBJ
+}
+
+func toBigInt(obj object.Object) *big.Int {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return big.NewInt(obj.Value)
+	case *object.BigInt:
+		return obj.Value
+	default:
+		return nil
+	}
+}
+
+func (ev *Evaluator) evalMinusPrefixOperatorExpression(right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		if right.Value == math.MinInt64 {
+			if ev.CheckedArithmetic {
+				return newError("integer overflow: -(%d)", right.Value)
+			}
+			return object.NewInteger(new(big.Int).Neg(toBigInt(right)))
+		}
+		return &object.Integer{Value: -right.Value}
+	case *object.BigInt:
+		return object.NewInteger(new(big.Int).Neg(right.Value))
+	default:
+		return newError("unknown operator: -%s", right.Type())
+	}
+}
+
+func (ev *Evaluator) evalIntegerInfixExpresson(operator string, left, right object.Object) object.Object {
+	l, lok := left.(*object.Integer)
+	r, rok := right.(*object.Integer)
+	if !lok || !rok {
+		return evalBigIntInfixExpression(operator, left, right)
+	}
+
+	leftVal := l.Value
+	rightVal := r.Value
+
+	switch operator {
+	case "+", "-", "*", "/":
+		if operator == "/" && rightVal == 0 {
+			return newError("division by zero")
+		}
+
+		result, overflow := integerArithmetic(operator, leftVal, rightVal)
+		if overflow {
+			if ev.CheckedArithmetic {
+				return newError("integer overflow: %d %s %d", leftVal, operator, rightVal)
+			}
+			return evalBigIntInfixExpression(operator, left, right)
+		}
+		return &object.Integer{Value: result}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// integerArithmetic applies operator with wrap-around semantics and reports
+// whether the result overflowed int64.
+func integerArithmetic(operator string, l, r int64) (int64, bool) {
+	switch operator {
+	case "+":
+		result := l + r
+		return result, (l^result)&(r^result) < 0
+	case "-":
+		result := l - r
+		return result, (l^r)&(l^result) < 0
+	case "*":
+		result := l * r
+		return result, l != 0 && (result/l != r || (l == -1 && r == math.MinInt64))
+	default:
+		return l / r, l == math.MinInt64 && r == -1
+	}
+}
+
+func evalBigIntInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := toBigInt(left)
+	rightVal := toBigInt(right)
+
+	switch operator {
+	case "+":
+		return object.NewInteger(new(big.Int).Add(leftVal, rightVal))
+	case "-":
+		return object.NewInteger(new(big.Int).Sub(leftVal, rightVal))
+	case "*":
+		return object.NewInteger(new(big.Int).Mul(leftVal, rightVal))
+	case "/":
+		if rightVal.Sign() == 0 {
+			return newError("division by zero")
+		}
+		// Quo truncates towards zero like int64 division
+		return object.NewInteger(new(big.Int).Quo(leftVal, rightVal))
+	case "<":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) > 0)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
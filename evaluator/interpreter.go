@@ -0,0 +1,105 @@
+package evaluator
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/smith-30/go-monkey/lexer"
+	"github.com/smith-30/go-monkey/object"
+	"github.com/smith-30/go-monkey/parser"
+)
+
+// DefaultMaxCallDepth keeps runaway recursion from exhausting the Go stack.
+const DefaultMaxCallDepth = 10000
+
+// Interpreter runs Monkey source code. Each Interpreter has its own builtin
+// table, output writers and root environment, so several of them can be
+// embedded in the same program without sharing state.
+//
+// Run may be called from several goroutines at once; each run has its own
+// evaluation state and the root environment is safe for concurrent use.
+// Settings of the embedded Evaluator and the builtin table may be changed
+// between runs, but not while a run is in progress.
+type Interpreter struct {
+	Evaluator
+
+	env    *object.Environment
+	macros *object.Environment
+}
+
+// NewInterpreter returns an Interpreter writing to os.Stdout and os.Stderr.
+func NewInterpreter() *Interpreter {
+	return &Interpreter{
+		Evaluator: Evaluator{
+			Stdout:   os.Stdout,
+			Stderr:   os.Stderr,
+			Limits:   Limits{MaxCallDepth: DefaultMaxCallDepth},
+			builtins: newBuiltins(),
+		},
+		env:    object.NewEnvironment(),
+		macros: object.NewEnvironment(),
+	}
+}
+
+// Env returns the root environment. Bindings made by one Run are visible
+// to the next.
+func (i *Interpreter) Env() *object.Environment {
+	return i.env
+}
+
+// Run parses and evaluates source in the root environment.
+// Evaluation stops with an error when ctx is done. Tasks started with
+// spawn that are still running when the program ends are stopped, and Run
+// returns once they have. Macros defined by
+// top-level lets are expanded before the program is evaluated and stay
+// defined for later runs.
+//
+// A parse failure is reported as *ParseError and an uncaught Monkey error
+// as *RuntimeError. Otherwise the value of the last statement is returned,
+// which is nil if that statement has no value, like let.
+func (i *Interpreter) Run(ctx context.Context, source string) (object.Object, error) {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, &ParseError{Errors: p.Errors()}
+	}
+
+	// per run state lives in a copy, the settings are shared
+	ev := i.Evaluator
+	ev.begin(ctx)
+	defer ev.finish()
+
+	// macros defined by one run can be used by the next
+	ev.DefineMacros(program, i.macros)
+	expanded, err := ev.ExpandMacros(program, i.macros)
+	if err != nil {
+		return nil, &RuntimeError{Err: err}
+	}
+
+	result := ev.Eval(expanded, i.env)
+
+	if err, ok := result.(*object.Error); ok && isError(err) {
+		return nil, &RuntimeError{Err: err}
+	}
+
+	return result, nil
+}
+
+// ParseError reports that the source given to Run could not be parsed.
+type ParseError struct {
+	Errors []string
+}
+
+func (e *ParseError) Error() string {
+	return "parse error: " + strings.Join(e.Errors, "; ")
+}
+
+// RuntimeError reports an error that was not caught by the script.
+type RuntimeError struct {
+	Err *object.Error
+}
+
+func (e *RuntimeError) Error() string {
+	return strings.TrimPrefix(e.Err.Inspect(), "ERROR: ")
+}
@@ -0,0 +1,270 @@
+package evaluator
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/smith-30/go-monkey/object"
+)
+
+func TestInterpreterRun(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+		err   string
+	}{
+		{name: "value", input: "1 + 2", exp: "3"},
+		{name: "let", input: "let a = 1;", exp: ""},
+		{name: "parse error", input: "let = 1;", err: "parse error: expected next token to be IDENT, got = instead; no prefix parse function for `=` found"},
+		{name: "runtime error", input: "1 / 0", err: "division by zero at 1:3"},
+		{name: "soft error", input: `error("soft")`, exp: "ERROR: soft at 1:6"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interp := NewInterpreter()
+			result, err := interp.Run(context.Background(), tt.input)
+
+			if tt.err != "" {
+				if err == nil {
+					t.Fatalf("want error %q, but got %v", tt.err, result)
+				}
+				if err.Error() != tt.err {
+					t.Errorf("want %q, but %q", tt.err, err.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			got := ""
+			if result != nil {
+				got = result.Inspect()
+			}
+			if got != tt.exp {
+				t.Errorf("want %q, but %q", tt.exp, got)
+			}
+		})
+	}
+}
+
+func TestHashLiteralEvaluationOrder(t *testing.T) {
+	var out bytes.Buffer
+	interp := NewInterpreter()
+	interp.Stdout = &out
+
+	input := `let show = fn(x) { puts(x); x };
+	let h = {show("c"): show(1), show("a"): show(2), show("b"): show(3)};
+	each(h, fn(k, v) { puts(k) })`
+	if _, err := interp.Run(context.Background(), input); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := "c\n1\na\n2\nb\n3\nc\na\nb\n"
+	if out.String() != exp {
+		t.Errorf("want %q, but %q", exp, out.String())
+	}
+}
+
+func TestPutsAndRepr(t *testing.T) {
+	var out bytes.Buffer
+	interp := NewInterpreter()
+	interp.Stdout = &out
+
+	input := `let h = {"name": "monkey", "tags": ["a", "b"]};
+	puts("text", h, repr("text"), repr(h), repr(1))`
+	if _, err := interp.Run(context.Background(), input); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := `text
+{"name": "monkey", "tags": ["a", "b"]}
+"text"
+{"name": "monkey", "tags": ["a", "b"]}
+1
+`
+	if out.String() != exp {
+		t.Errorf("want %q, but %q", exp, out.String())
+	}
+}
+
+func TestInterpreterIsolation(t *testing.T) {
+	var out1, out2 bytes.Buffer
+
+	i1 := NewInterpreter()
+	i1.Stdout = &out1
+	i2 := NewInterpreter()
+	i2.Stdout = &out2
+
+	if _, err := i1.Run(context.Background(), `let x = "one"; puts(x)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i2.Run(context.Background(), `let x = "two"; puts(x, 2)`); err != nil {
+		t.Fatal(err)
+	}
+
+	if out1.String() != "one\n" {
+		t.Errorf("want %q, but %q", "one\n", out1.String())
+	}
+	if out2.String() != "two\n2\n" {
+		t.Errorf("want %q, but %q", "two\n2\n", out2.String())
+	}
+
+	// bindings persist between runs of the same interpreter only
+	result, err := i1.Run(context.Background(), "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Inspect() != "one" {
+		t.Errorf("want %q, but %q", "one", result.Inspect())
+	}
+}
+
+func TestInterpreterLimits(t *testing.T) {
+	loop := `let loop = fn(n) { loop(n + 1) }; loop(0)`
+
+	t.Run("call depth", func(t *testing.T) {
+		interp := NewInterpreter()
+		interp.Limits.MaxCallDepth = 100
+
+		_, err := interp.Run(context.Background(), loop)
+		if err == nil {
+			t.Fatal("want error, but got nil")
+		}
+
+		rerr, ok := err.(*RuntimeError)
+		if !ok {
+			t.Fatalf("error is not *RuntimeError. got=%T", err)
+		}
+		if rerr.Err.Message != "call depth limit exceeded: 100" {
+			t.Errorf("wrong message %q", rerr.Err.Message)
+		}
+		// the call that was refused is part of the stack
+		if len(rerr.Err.Stack) != 101 {
+			t.Errorf("want %d frames, but %d", 101, len(rerr.Err.Stack))
+		}
+	})
+
+	t.Run("steps", func(t *testing.T) {
+		interp := NewInterpreter()
+		interp.Limits.MaxSteps = 1000
+
+		_, err := interp.Run(context.Background(), loop)
+		if err == nil || err.(*RuntimeError).Err.Message != "step limit exceeded: 1000" {
+			t.Errorf("want step limit error, but %v", err)
+		}
+
+		// the step count starts over with every run
+		if _, err := interp.Run(context.Background(), "1 + 1"); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	})
+
+	t.Run("steps of tasks", func(t *testing.T) {
+		interp := NewInterpreter()
+		interp.Limits.MaxSteps = 2000
+
+		// every task takes its steps from the same budget
+		_, err := interp.Run(context.Background(), `
+let work = fn(n) { if (n > 0) { work(n - 1) } else { n } };
+let ts = map(range(10), fn(i) { spawn(work, 100) });
+map(collect(ts), await)`)
+		if err == nil || err.(*RuntimeError).Err.Message != "step limit exceeded: 2000" {
+			t.Errorf("want step limit error, but %v", err)
+		}
+	})
+
+	t.Run("steps of generators", func(t *testing.T) {
+		interp := NewInterpreter()
+		interp.Limits.MaxSteps = 2000
+
+		_, err := interp.Run(context.Background(), `
+let count = fn(n) { if (n > 0) { yield n; yield* count(n - 1); } };
+collect(count(500))`)
+		if err == nil || err.(*RuntimeError).Err.Message != "step limit exceeded: 2000" {
+			t.Errorf("want step limit error, but %v", err)
+		}
+	})
+
+	t.Run("tasks", func(t *testing.T) {
+		interp := NewInterpreter()
+		interp.Limits.MaxTasks = 2
+
+		_, err := interp.Run(context.Background(), `
+let ch = channel();
+spawn(receive, ch);
+spawn(receive, ch);
+spawn(receive, ch)`)
+		if err == nil || err.(*RuntimeError).Err.Message != "task limit exceeded: 2" {
+			t.Errorf("want task limit error, but %v", err)
+		}
+
+		// finished tasks do not count
+		result, err := interp.Run(context.Background(), `
+let ts = [await(spawn(len, "a")), await(spawn(len, "ab")), await(spawn(len, "abc"))];
+ts`)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if result.Inspect() != "[1, 2, 3]" {
+			t.Errorf("want %q, but %q", "[1, 2, 3]", result.Inspect())
+		}
+	})
+
+	t.Run("context", func(t *testing.T) {
+		interp := NewInterpreter()
+		interp.Limits.MaxCallDepth = 0
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := interp.Run(ctx, loop)
+		if err == nil || err.(*RuntimeError).Err.Message != "evaluation stopped: context canceled" {
+			t.Errorf("want cancellation error, but %v", err)
+		}
+	})
+}
+
+func TestInterpreterDecodeConfig(t *testing.T) {
+	type server struct {
+		Host string `monkey:"host"`
+		Port int    `monkey:"port"`
+	}
+	type config struct {
+		Name    string   `monkey:"name"`
+		Servers []server `monkey:"servers"`
+		Debug   bool     `monkey:"debug"`
+	}
+
+	interp := NewInterpreter()
+	env, err := object.FromGo(map[string]interface{}{"base_port": 8000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	interp.Env().Set("env", env)
+
+	result, err := interp.Run(context.Background(), `
+let server = fn(host, offset) { {"host": host, "port": env["base_port"] + offset} };
+{
+	"name": "api",
+	"servers": map(["a", "b"], fn(h) { server(h, len(h)) }),
+	"debug": false
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got config
+	if err := object.Decode(result, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := config{Name: "api", Servers: []server{{"a", 8001}, {"b", 8001}}}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("want %+v, but %+v", exp, got)
+	}
+}
@@ -0,0 +1,113 @@
+package evaluator
+
+import (
+	"github.com/smith-30/go-monkey/ast"
+	"github.com/smith-30/go-monkey/object"
+)
+
+// DefineMacros moves the macros bound by top-level let statements of
+// program into env.
+func DefineMacros(program *ast.Program, env *object.Environment) {
+	(&Evaluator{}).DefineMacros(program, env)
+}
+
+// ExpandMacros returns program with every call of a macro in env replaced
+// by the AST the macro returns.
+func ExpandMacros(program ast.Node, env *object.Environment) (ast.Node, *object.Error) {
+	return (&Evaluator{}).ExpandMacros(program, env)
+}
+
+// DefineMacros moves the macros bound by top-level let statements of
+// program into env.
+func (ev *Evaluator) DefineMacros(program *ast.Program, env *object.Environment) {
+	statements := program.Statements[:0]
+
+	for _, stmt := range program.Statements {
+		let, ok := stmt.(*ast.LetStatement)
+		if !ok {
+			statements = append(statements, stmt)
+			continue
+		}
+
+		lit, ok := let.Value.(*ast.MacroLiteral)
+		if !ok {
+			statements = append(statements, stmt)
+			continue
+		}
+
+		env.Set(let.Name.Value, &object.Macro{Parameters: lit.Parameters, Body: lit.Body, Env: env})
+	}
+
+	program.Statements = statements
+}
+
+// ExpandMacros returns program with every call of a macro in env replaced
+// by the AST the macro returns. Macros are called with their arguments
+// quoted and must return a quote. Expansion is done bottom-up, so a macro
+// receives its arguments already expanded.
+func (ev *Evaluator) ExpandMacros(program ast.Node, env *object.Environment) (ast.Node, *object.Error) {
+	var failed *object.Error
+
+	expanded := ast.Modify(program, func(node ast.Node) ast.Node {
+		call, ok := node.(*ast.CallExpression)
+		if !ok || failed != nil {
+			return node
+		}
+
+		macro, ok := isMacroCall(call, env)
+		if !ok {
+			return node
+		}
+
+		if len(call.Arguments) != len(macro.Parameters) {
+			failed = newError("wrong number of arguments. got=%d, want=%d", len(call.Arguments), len(macro.Parameters))
+			failed.Pos = call.Pos()
+			return node
+		}
+
+		evalEnv := object.NewEnclosedEnvironment(macro.Env)
+		for i, param := range macro.Parameters {
+			evalEnv.Set(param.Value, &object.Quote{Node: call.Arguments[i]})
+		}
+
+		evaluated := unwrapReturnValue(ev.Eval(macro.Body, evalEnv))
+		if err, ok := evaluated.(*object.Error); ok && isError(err) {
+			failed = err
+			return node
+		}
+
+		quote, ok := evaluated.(*object.Quote)
+		if !ok {
+			failed = newError("macro must return QUOTE, got=%s", typeOf(evaluated))
+			failed.Pos = call.Pos()
+			return node
+		}
+
+		return quote.Node
+	})
+
+	return expanded, failed
+}
+
+func isMacroCall(call *ast.CallExpression, env *object.Environment) (*object.Macro, bool) {
+	id, ok := call.Function.(*ast.Identifier)
+	if !ok {
+		return nil, false
+	}
+
+	obj, ok := env.Get(id.Value)
+	if !ok {
+		return nil, false
+	}
+
+	macro, ok := obj.(*object.Macro)
+	return macro, ok
+}
+
+// typeOf is the type of obj, which may be nil for statements without a value.
+func typeOf(obj object.Object) object.ObjectType {
+	if obj == nil {
+		return object.NULL_OBJ
+	}
+	return obj.Type()
+}
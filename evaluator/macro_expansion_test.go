@@ -0,0 +1,241 @@
+package evaluator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smith-30/go-monkey/ast"
+	"github.com/smith-30/go-monkey/lexer"
+	"github.com/smith-30/go-monkey/object"
+	"github.com/smith-30/go-monkey/parser"
+)
+
+func TestDefineMacros(t *testing.T) {
+	input := `
+let number = 1;
+let function = fn(x, y) { x + y };
+let mymacro = macro(x, y) { x + y; };
+`
+
+	env := object.NewEnvironment()
+	program := testParseProgram(t, input)
+
+	DefineMacros(program, env)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("Wrong number of statements. got=%d", len(program.Statements))
+	}
+
+	if _, ok := env.Get("number"); ok {
+		t.Fatalf("number should not be defined")
+	}
+	if _, ok := env.Get("function"); ok {
+		t.Fatalf("function should not be defined")
+	}
+
+	obj, ok := env.Get("mymacro")
+	if !ok {
+		t.Fatalf("macro not in environment.")
+	}
+
+	macro, ok := obj.(*object.Macro)
+	if !ok {
+		t.Fatalf("object is not Macro. got=%T (%+v)", obj, obj)
+	}
+
+	if len(macro.Parameters) != 2 {
+		t.Fatalf("Wrong number of macro parameters. got=%d", len(macro.Parameters))
+	}
+	if macro.Parameters[0].String() != "x" || macro.Parameters[1].String() != "y" {
+		t.Errorf("wrong parameters. got=%s, %s", macro.Parameters[0], macro.Parameters[1])
+	}
+	if macro.Body.String() != "{ (x + y) }" {
+		t.Errorf("body is not %q. got=%q", "{ (x + y) }", macro.Body.String())
+	}
+}
+
+func TestExpandMacros(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+	}{
+		{
+			name: "infix",
+			input: `
+let infixExpression = macro() { quote(1 + 2); };
+
+infixExpression();
+`,
+			exp: `(1 + 2)`,
+		},
+		{
+			name: "reverse",
+			input: `
+let reverse = macro(a, b) { quote(unquote(b) - unquote(a)); };
+
+reverse(2 + 2, 10 - 5);
+`,
+			exp: `(10 - 5) - (2 + 2)`,
+		},
+		{
+			name: "unless",
+			input: `
+let unless = macro(condition, consequence, alternative) {
+	quote(if (!(unquote(condition))) {
+		unquote(consequence);
+	} else {
+		unquote(alternative);
+	});
+};
+
+unless(10 > 5, puts("not greater"), puts("greater"));
+`,
+			exp: `if (!(10 > 5)) { puts("not greater") } else { puts("greater") }`,
+		},
+		{
+			name: "nested",
+			input: `
+let double = macro(x) { quote(unquote(x) * 2); };
+
+double(double(1));
+`,
+			exp: `((1 * 2) * 2)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp := testParseProgram(t, tt.exp)
+			program := testParseProgram(t, tt.input)
+
+			env := object.NewEnvironment()
+			DefineMacros(program, env)
+			expanded, err := ExpandMacros(program, env)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Inspect())
+			}
+
+			if expanded.String() != exp.String() {
+				t.Errorf("want %q, but %q", exp.String(), expanded.String())
+			}
+		})
+	}
+}
+
+func TestExpandMacrosErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+	}{
+		{
+			name:  "arity",
+			input: "let m = macro(x) { x; };\nm(1, 2);",
+			exp:   "wrong number of arguments. got=2, want=1 at 2:2",
+		},
+		{
+			name:  "not a quote",
+			input: "let m = macro(x) { 1; };\nm(2);",
+			exp:   "macro must return QUOTE, got=INTEGER at 2:2",
+		},
+		{
+			name:  "body error",
+			input: "let m = macro(x) { missing; };\nm(2);",
+			exp:   "identifier not found: missing at 1:20",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := testParseProgram(t, tt.input)
+
+			env := object.NewEnvironment()
+			DefineMacros(program, env)
+			_, err := ExpandMacros(program, env)
+			if err == nil {
+				t.Fatalf("want error %q, but got none", tt.exp)
+			}
+
+			if got := (&RuntimeError{Err: err}).Error(); got != tt.exp {
+				t.Errorf("want %q, but %q", tt.exp, got)
+			}
+		})
+	}
+}
+
+func TestInterpreterMacros(t *testing.T) {
+	var out bytes.Buffer
+	interp := NewInterpreter()
+	interp.Stdout = &out
+
+	// macros stay defined for later runs
+	testRun(t, interp, `
+let unless = macro(condition, consequence, alternative) {
+	quote(if (!(unquote(condition))) {
+		unquote(consequence);
+	} else {
+		unquote(alternative);
+	});
+};
+let assert = macro(condition, message) {
+	quote(if (!(unquote(condition))) {
+		throw unquote(message);
+	} else {
+		true
+	});
+};
+let with_timing = macro(label, body) {
+	quote((fn() {
+		let start = clock();
+		let r = unquote(body);
+		puts(unquote(label), clock() - start);
+		r
+	})());
+};
+1`, "1", "")
+
+	testRun(t, interp, `unless(10 > 5, "not greater", "greater")`, `greater`, "")
+	testRun(t, interp, `assert(1 + 1 == 2, "math is broken")`, `true`, "")
+	testRun(t, interp, `assert(1 + 1 == 3, "math is broken")`, "", `math is broken at 11:3`)
+
+	out.Reset()
+	testRun(t, interp, `with_timing("sum", 20 + 22)`, `42`, "")
+	if !strings.HasPrefix(out.String(), "sum\n") {
+		t.Errorf("want label printed, but %q", out.String())
+	}
+}
+
+func TestMacroErrorFinishes(t *testing.T) {
+	var out bytes.Buffer
+	interp := NewInterpreter()
+	interp.Stdout = &out
+
+	// the task started by the macro is stopped although expansion fails
+	testRun(t, interp, `
+let m = macro() { spawn(fn() { select([channel()], 20); puts("late") }); 1 };
+m()`, "", "macro must return QUOTE, got=INTEGER at 3:2")
+
+	time.Sleep(50 * time.Millisecond)
+	if out.Len() != 0 {
+		t.Errorf("want no output after Run returned, but %q", out.String())
+	}
+}
+
+func TestMacroLiteralOutsideLet(t *testing.T) {
+	interp := NewInterpreter()
+	testRun(t, interp, `let f = fn() { macro(x) { x } }; f()`, "", "macros must be defined with a top-level let at 1:16\n\tin f, called at 1:35")
+}
+
+func testParseProgram(t *testing.T, input string) *ast.Program {
+	t.Helper()
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parse errors: %v", p.Errors())
+	}
+	return program
+}
@@ -0,0 +1,186 @@
+package evaluator
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/smith-30/go-monkey/object"
+)
+
+// Policy restricts what scripts may do. It is consulted when a builtin is
+// looked up by name, when a builtin of the builtin table is called and when
+// a builtin asks for a capability with CallContext.Check. Denials are
+// reported as errors.
+//
+// Everything not listed is denied, so the zero Policy only allows plain
+// computation. An Evaluator without a Policy allows everything.
+type Policy struct {
+	// Builtins are the names of the builtins scripts may use.
+	Builtins []string
+
+	// Paths are the files and directories file builtins may access.
+	// A directory grants access to everything below it.
+	Paths []string
+
+	// Hosts are the hosts network builtins may connect to. An entry
+	// without a port allows every port of the host.
+	Hosts []string
+
+	// Output allows writing to Stdout and Stderr.
+	Output bool
+}
+
+// AllowsBuiltin reports whether the builtin called name may be used.
+func (p *Policy) AllowsBuiltin(name string) bool {
+	for _, b := range p.Builtins {
+		if b == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsPath reports whether path is one of p.Paths or lies below one of
+// them. Relative paths are resolved against the working directory, and
+// symbolic links are followed on both sides, so a link inside an allowed
+// directory that points outside of it is denied, and so is ".." after it.
+func (p *Policy) AllowsPath(path string) bool {
+	path, err := resolvePath(path)
+	if err != nil {
+		return false
+	}
+
+	for _, allowed := range p.Paths {
+		allowed, err := resolvePath(allowed)
+		if err != nil {
+			continue
+		}
+		if path == allowed || strings.HasPrefix(path, strings.TrimSuffix(allowed, string(filepath.Separator))+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath returns the absolute form of path with symbolic links
+// resolved. Like the operating system, it resolves one component at a time,
+// so ".." after a link leads to the parent of its target. The part of path
+// that does not exist yet, like a file about to be created, is kept as it is.
+func resolvePath(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		// not filepath.Join, which would apply ".." before links are resolved
+		path = wd + string(filepath.Separator) + path
+	}
+
+	links := 0
+	return resolveFrom(rootOf(path), path, &links)
+}
+
+// maxLinks bounds the links followed for a single path, which ends loops.
+const maxLinks = 255
+
+// resolveFrom resolves the components of path one by one, starting in dir.
+func resolveFrom(dir, path string, links *int) (string, error) {
+	path = path[len(filepath.VolumeName(path)):]
+	for _, name := range strings.Split(path, string(filepath.Separator)) {
+		switch name {
+		case "", ".":
+			continue
+		case "..":
+			// dir has no links left, so its parent is the real one
+			dir = filepath.Dir(dir)
+			continue
+		}
+
+		next := filepath.Join(dir, name)
+		info, err := os.Lstat(next)
+		switch {
+		case os.IsNotExist(err):
+			dir = next
+		case err != nil:
+			return "", err
+		case info.Mode()&os.ModeSymlink != 0:
+			if *links++; *links > maxLinks {
+				return "", fmt.Errorf("%s: too many links", next)
+			}
+			target, err := os.Readlink(next)
+			if err != nil {
+				return "", err
+			}
+			from := dir
+			if filepath.IsAbs(target) {
+				from = rootOf(target)
+			}
+			if dir, err = resolveFrom(from, target, links); err != nil {
+				return "", err
+			}
+		default:
+			dir = next
+		}
+	}
+	return dir, nil
+}
+
+// rootOf returns the root directory of the absolute path.
+func rootOf(path string) string {
+	return filepath.VolumeName(path) + string(filepath.Separator)
+}
+
+// AllowsHost reports whether host, which may include a port, matches one
+// of p.Hosts. Host names are compared case-insensitively.
+func (p *Policy) AllowsHost(host string) bool {
+	name := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		name = h
+	}
+
+	for _, allowed := range p.Hosts {
+		if strings.EqualFold(allowed, host) || strings.EqualFold(allowed, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// check reports an error if the policy of ev denies access to resource.
+func (ev *Evaluator) check(c object.Capability, resource string) *object.Error {
+	p := ev.Policy
+	if p == nil {
+		return nil
+	}
+
+	switch c {
+	case object.FileAccess:
+		if p.AllowsPath(resource) {
+			return nil
+		}
+	case object.NetworkAccess:
+		if p.AllowsHost(resource) {
+			return nil
+		}
+	case object.OutputAccess:
+		if p.Output {
+			return nil
+		}
+		return newError("%s denied by policy", c)
+	}
+
+	return newError("%s to %s denied by policy", c, resource)
+}
+
+// checkBuiltin reports an error if the policy of ev denies the builtin
+// called name.
+func (ev *Evaluator) checkBuiltin(name string) *object.Error {
+	if ev.Policy == nil || ev.Policy.AllowsBuiltin(name) {
+		return nil
+	}
+
+	return newError("builtin %s denied by policy", name)
+}
@@ -0,0 +1,142 @@
+package evaluator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/smith-30/go-monkey/object"
+)
+
+func TestPolicy(t *testing.T) {
+	dir := filepath.Join("/srv", "data")
+
+	// stand-ins for builtins that would touch files and the network
+	newInterp := func(p *Policy) *Interpreter {
+		interp := NewInterpreter()
+		interp.Stdout = &bytes.Buffer{}
+		interp.Policy = p
+		interp.Register("open", 1, "", func(ctx object.CallContext, args ...object.Object) object.Object {
+			path := args[0].(*object.String).Value
+			if err := ctx.Check(object.FileAccess, path); err != nil {
+				return err
+			}
+			return &object.String{Value: "opened " + path}
+		})
+		interp.Register("fetch", 1, "", func(ctx object.CallContext, args ...object.Object) object.Object {
+			host := args[0].(*object.String).Value
+			if err := ctx.Check(object.NetworkAccess, host); err != nil {
+				return err
+			}
+			return &object.String{Value: "fetched " + host}
+		})
+		return interp
+	}
+
+	untrusted := &Policy{
+		Builtins: []string{"len", "map", "open", "fetch"},
+		Paths:    []string{dir},
+		Hosts:    []string{"example.com", "api.example.com:443"},
+	}
+
+	tests := []struct {
+		name   string
+		policy *Policy
+		input  string
+		exp    string
+		err    string
+	}{
+		{name: "trusted", input: `puts("hi"); open("/etc/passwd")`, exp: "opened /etc/passwd"},
+		{name: "allowed builtin", policy: untrusted, input: `len([1, 2])`, exp: "2"},
+		{name: "denied builtin", policy: untrusted, input: `first([1, 2])`, err: "builtin first denied by policy at 1:1"},
+		{name: "shadowed builtin", policy: untrusted, input: `let first = fn(a) { a[0] }; first([1, 2])`, exp: "1"},
+		{name: "rebound builtin name", policy: untrusted, input: `let puts = 1; len("a")`, exp: "1"},
+		{name: "allowed path", policy: untrusted, input: `open("/srv/data/a.txt")`, exp: "opened /srv/data/a.txt"},
+		{name: "allowed dir", policy: untrusted, input: `open("/srv/data")`, exp: "opened /srv/data"},
+		{name: "denied path", policy: untrusted, input: `open("/srv/database")`, err: "file access to /srv/database denied by policy at 1:5"},
+		{name: "path traversal", policy: untrusted, input: `open("/srv/data/../secret")`, err: "file access to /srv/data/../secret denied by policy at 1:5"},
+		{name: "allowed host", policy: untrusted, input: `fetch("example.com:8080")`, exp: "fetched example.com:8080"},
+		{name: "allowed port", policy: untrusted, input: `fetch("api.example.com:443")`, exp: "fetched api.example.com:443"},
+		{name: "denied port", policy: untrusted, input: `fetch("api.example.com:80")`, err: "network access to api.example.com:80 denied by policy at 1:6"},
+		{name: "denied host", policy: untrusted, input: `map(["evil.com"], fetch)`, err: "network access to evil.com denied by policy at 1:4"},
+		{name: "zero policy", policy: &Policy{}, input: `1 + 1`, exp: "2"},
+		{name: "zero policy builtin", policy: &Policy{}, input: `len("")`, err: "builtin len denied by policy at 1:1"},
+		{name: "output", policy: &Policy{Builtins: []string{"puts"}}, input: `puts(1)`, err: "output denied by policy at 1:5"},
+		{name: "allowed output", policy: &Policy{Builtins: []string{"puts"}, Output: true}, input: `puts(1)`, exp: "null"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRun(t, newInterp(tt.policy), tt.input, tt.exp, tt.err)
+		})
+	}
+}
+
+func TestPolicySymlinks(t *testing.T) {
+	root := t.TempDir()
+	allowed := filepath.Join(root, "allowed")
+	outside := filepath.Join(root, "outside")
+	for _, dir := range []string{allowed, outside, filepath.Join(allowed, "sub")} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	links := map[string]string{
+		filepath.Join(allowed, "escape"):   outside,
+		filepath.Join(allowed, "dangling"): filepath.Join(outside, "new.txt"),
+		filepath.Join(allowed, "inner"):    filepath.Join(allowed, "sub"),
+		filepath.Join(root, "alias"):       allowed,
+		filepath.Join(allowed, "loop"):     filepath.Join(allowed, "loop"),
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, link); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := &Policy{Paths: []string{allowed}}
+	tests := []struct {
+		name string
+		path string
+		exp  bool
+	}{
+		{"file", filepath.Join(allowed, "a.txt"), true},
+		{"new directory", filepath.Join(allowed, "x", "y.txt"), true},
+		{"link to outside", filepath.Join(allowed, "escape", "secret"), false},
+		{"parent of link to outside", allowed + "/escape/../secret", false},
+		{"parent of link inside", allowed + "/inner/../a.txt", true},
+		{"parent", allowed + "/sub/../a.txt", true},
+		{"parent of allowed", allowed + "/../outside/a.txt", false},
+		{"link loop", filepath.Join(allowed, "loop", "a.txt"), false},
+		{"dangling link to outside", filepath.Join(allowed, "dangling"), false},
+		{"link inside", filepath.Join(allowed, "inner", "a.txt"), true},
+		{"link to allowed", filepath.Join(root, "alias", "a.txt"), true},
+		{"outside", filepath.Join(outside, "a.txt"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.AllowsPath(tt.path); got != tt.exp {
+				t.Errorf("want %t, but %t", tt.exp, got)
+			}
+		})
+	}
+
+	// an allowed path given through a link is resolved as well
+	p = &Policy{Paths: []string{filepath.Join(root, "alias")}}
+	if !p.AllowsPath(filepath.Join(allowed, "a.txt")) {
+		t.Errorf("path below the target of an allowed link is denied")
+	}
+}
+
+func TestPolicyChangedBetweenRuns(t *testing.T) {
+	interp := NewInterpreter()
+	testRun(t, interp, `let f = len; f("a")`, "1", "")
+
+	interp.Policy = &Policy{}
+	testRun(t, interp, `f("abc")`, "", "builtin len denied by policy at 1:2")
+
+	// builtins that are not part of the table are up to the host
+	interp.Env().Set("g", &object.Builtin{Name: "len", Fn: builtins["len"].Fn})
+	testRun(t, interp, `g("abc")`, "3", "")
+}
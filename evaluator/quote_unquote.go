@@ -0,0 +1,95 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/smith-30/go-monkey/ast"
+	"github.com/smith-30/go-monkey/object"
+	"github.com/smith-30/go-monkey/token"
+)
+
+// quote returns node unevaluated, except for the calls of unquote in it,
+// which are replaced by the AST of their evaluated argument.
+func (ev *Evaluator) quote(call *ast.CallExpression, env *object.Environment) object.Object {
+	if len(call.Arguments) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(call.Arguments))
+	}
+
+	node, err := ev.evalUnquoteCalls(call.Arguments[0], env)
+	if err != nil {
+		return err
+	}
+
+	return &object.Quote{Node: node}
+}
+
+func (ev *Evaluator) evalUnquoteCalls(quoted ast.Node, env *object.Environment) (ast.Node, *object.Error) {
+	var failed *object.Error
+
+	node := ast.Modify(quoted, func(node ast.Node) ast.Node {
+		call, ok := node.(*ast.CallExpression)
+		if !ok || !isCallTo(call, "unquote") || failed != nil {
+			return node
+		}
+
+		if len(call.Arguments) != 1 {
+			failed = newError("wrong number of arguments. got=%d, want=1", len(call.Arguments))
+			failed.Pos = call.Pos()
+			return node
+		}
+
+		unquoted := ev.Eval(call.Arguments[0], env)
+		if err, ok := unquoted.(*object.Error); ok && isError(err) {
+			failed = err
+			return node
+		}
+
+		converted, ok := objectToASTNode(unquoted, call.Pos())
+		if !ok {
+			failed = newError("cannot unquote %s", unquoted.Type())
+			failed.Pos = call.Pos()
+			return node
+		}
+		return converted
+	})
+
+	return node, failed
+}
+
+// isCallTo reports whether call calls the identifier name, like quote(x).
+func isCallTo(call *ast.CallExpression, name string) bool {
+	id, ok := call.Function.(*ast.Identifier)
+	return ok && id.Value == name
+}
+
+// objectToASTNode returns an expression evaluating to obj, placed at pos.
+func objectToASTNode(obj object.Object, pos token.Position) (ast.Node, bool) {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		t := token.Token{Type: token.INT, Literal: fmt.Sprintf("%d", obj.Value), Pos: pos}
+		return &ast.IntegerLiteral{Token: t, Value: obj.Value}, true
+	case *object.String:
+		t := token.Token{Type: token.STRING, Literal: obj.Value, Pos: pos}
+		return &ast.StringLiteral{Token: t, Value: obj.Value}, true
+	case *object.Boolean:
+		t := token.Token{Type: token.FALSE, Literal: "false", Pos: pos}
+		if obj.Value {
+			t = token.Token{Type: token.TRUE, Literal: "true", Pos: pos}
+		}
+		return &ast.Boolean{Token: t, Value: obj.Value}, true
+	case *object.Array:
+		lit := &ast.ArrayLiteral{Token: token.Token{Type: token.LBRACKET, Literal: "[", Pos: pos}}
+		for _, e := range obj.Elements {
+			n, ok := objectToASTNode(e, pos)
+			if !ok {
+				return nil, false
+			}
+			lit.Elements = append(lit.Elements, n.(ast.Expression))
+		}
+		return lit, true
+	case *object.Quote:
+		return obj.Node, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,90 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/smith-30/go-monkey/object"
+)
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		input string
+		exp   string
+	}{
+		{`quote(5)`, `5`},
+		{`quote(5 + 8)`, `(5 + 8)`},
+		{`quote(foobar)`, `foobar`},
+		{`quote(foobar + barfoo)`, `(foobar + barfoo)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			testQuote(t, testEval(tt.input), tt.exp)
+		})
+	}
+}
+
+func TestQuoteUnquote(t *testing.T) {
+	tests := []struct {
+		input string
+		exp   string
+	}{
+		{`quote(unquote(4))`, `4`},
+		{`quote(unquote(4 + 4))`, `8`},
+		{`quote(8 + unquote(4 + 4))`, `(8 + 8)`},
+		{`quote(unquote(4 + 4) + 8)`, `(8 + 8)`},
+		{`let foobar = 8; quote(foobar)`, `foobar`},
+		{`let foobar = 8; quote(unquote(foobar))`, `8`},
+		{`quote(unquote(true))`, `true`},
+		{`quote(unquote(true == false))`, `false`},
+		{`quote(unquote("monkey"))`, `"monkey"`},
+		{`quote(unquote([1, 2]))`, `[1, 2]`},
+		{`quote(unquote(quote(4 + 4)))`, `(4 + 4)`},
+		{`let quotedInfixExpression = quote(4 + 4); quote(unquote(4 + 4) + unquote(quotedInfixExpression))`, `(8 + (4 + 4))`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			testQuote(t, testEval(tt.input), tt.exp)
+		})
+	}
+}
+
+func TestQuoteErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		exp   string
+	}{
+		{`quote(1, 2)`, `wrong number of arguments. got=2, want=1`},
+		{`quote(unquote())`, `wrong number of arguments. got=0, want=1`},
+		{`quote(unquote(fn(x) { x }))`, `cannot unquote FUNCTION`},
+		{`quote(unquote(missing))`, `identifier not found: missing`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			err, ok := testEval(tt.input).(*object.Error)
+			if !ok {
+				t.Fatalf("object is not Error. got=%T", testEval(tt.input))
+			}
+			if err.Message != tt.exp {
+				t.Errorf("want %q, but %q", tt.exp, err.Message)
+			}
+		})
+	}
+}
+
+func testQuote(t *testing.T, obj object.Object, exp string) {
+	t.Helper()
+
+	quote, ok := obj.(*object.Quote)
+	if !ok {
+		t.Fatalf("expected *object.Quote. got=%T (%+v)", obj, obj)
+	}
+	if quote.Node == nil {
+		t.Fatalf("quote.Node is nil")
+	}
+	if quote.Node.String() != exp {
+		t.Errorf("want %q, but %q", exp, quote.Node.String())
+	}
+}
@@ -0,0 +1,117 @@
+package evaluator
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/smith-30/go-monkey/object"
+)
+
+// Register makes fn callable from scripts run by i under name, replacing any
+// builtin of the same name. Calls with a number of arguments other than
+// arity are rejected before fn runs unless arity is object.Variadic.
+func (i *Interpreter) Register(name string, arity int, doc string, fn object.BuiltinFunction) {
+	i.builtins[name] = NewBuiltin(name, arity, doc, fn)
+}
+
+// RegisterFunc registers an ordinary Go function, see WrapFunc.
+func (i *Interpreter) RegisterFunc(name, doc string, fn interface{}) error {
+	b, err := WrapFunc(name, doc, fn)
+	if err != nil {
+		return err
+	}
+
+	i.builtins[name] = b
+	return nil
+}
+
+// NewBuiltin returns a builtin that checks the number of arguments before
+// calling fn.
+func NewBuiltin(name string, arity int, doc string, fn object.BuiltinFunction) *object.Builtin {
+	return &object.Builtin{
+		Name:  name,
+		Arity: arity,
+		Doc:   doc,
+		Fn: func(ctx object.CallContext, args ...object.Object) object.Object {
+			if arity != object.Variadic && len(args) != arity {
+				return newError("wrong number of arguments. got=%d, want=%d", len(args), arity)
+			}
+			return fn(ctx, args...)
+		},
+	}
+}
+
+var (
+	objectType = reflect.TypeOf((*object.Object)(nil)).Elem()
+	ctxType    = reflect.TypeOf((*object.CallContext)(nil)).Elem()
+	errorType  = reflect.TypeOf((*error)(nil)).Elem()
+	bigIntType = reflect.TypeOf((*big.Int)(nil))
+)
+
+// WrapFunc adapts an ordinary Go function such as
+// func(string, int) (string, error) to a builtin.
+//
+// Parameters may be integers, strings, bools, *big.Int, object.Object or
+// slices of those, and so may the result. A function may return nothing,
+// a value, an error or a value and an error; a non-nil error is returned to
+// the script as a Monkey error, and so is a panic. Variadic functions are
+// supported. Calls are made by object.NewGoFunc.
+//
+// A function whose first parameter is object.CallContext is given the
+// context of the call, through which it can call Monkey functions passed
+// to it.
+func WrapFunc(name, doc string, fn interface{}) (*object.Builtin, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		return nil, fmt.Errorf("builtin %s: %T is not a function", name, fn)
+	}
+
+	t := v.Type()
+	for i := firstArg(t); i < t.NumIn(); i++ {
+		in := t.In(i)
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			in = in.Elem()
+		}
+		if !convertibleType(in) {
+			return nil, fmt.Errorf("builtin %s: unsupported parameter type %s", name, in)
+		}
+	}
+
+	switch {
+	case t.NumOut() > 2:
+		return nil, fmt.Errorf("builtin %s: too many results", name)
+	case t.NumOut() == 2 && t.Out(1) != errorType:
+		return nil, fmt.Errorf("builtin %s: second result must be error, got %s", name, t.Out(1))
+	case t.NumOut() >= 1 && t.Out(0) != errorType && !convertibleType(t.Out(0)):
+		return nil, fmt.Errorf("builtin %s: unsupported result type %s", name, t.Out(0))
+	}
+
+	return object.NewGoFunc(name, doc, v), nil
+}
+
+// firstArg is the index of the first parameter of t filled from script
+// arguments.
+func firstArg(t reflect.Type) int {
+	if t.NumIn() > 0 && t.In(0) == ctxType {
+		return 1
+	}
+	return 0
+}
+
+func convertibleType(t reflect.Type) bool {
+	if t == objectType || t == bigIntType || t.Implements(objectType) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.String, reflect.Bool:
+		return true
+	case reflect.Slice:
+		return convertibleType(t.Elem())
+	default:
+		return false
+	}
+}
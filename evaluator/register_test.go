@@ -0,0 +1,250 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/smith-30/go-monkey/object"
+)
+
+func TestInterpreterRegister(t *testing.T) {
+	interp := NewInterpreter()
+	interp.Register("twice", 1, "twice(x) returns [x, x].", func(ctx object.CallContext, args ...object.Object) object.Object {
+		return &object.Array{Elements: []object.Object{args[0], args[0]}}
+	})
+
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+		err   string
+	}{
+		{name: "call", input: "twice(1)", exp: "[1, 1]"},
+		{name: "arity", input: "twice(1, 2)", err: "wrong number of arguments. got=2, want=1 at 1:6"},
+		{name: "inspect", input: "twice", exp: "builtin function twice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRun(t, interp, tt.input, tt.exp, tt.err)
+		})
+	}
+
+	// other interpreters do not see the registration
+	if _, err := NewInterpreter().Run(context.Background(), "twice(1)"); err == nil {
+		t.Errorf("builtin leaked into another interpreter")
+	}
+}
+
+func TestInterpreterRegisterFunc(t *testing.T) {
+	interp := NewInterpreter()
+
+	funcs := map[string]interface{}{
+		"repeat": func(s string, n int) (string, error) {
+			if n < 0 {
+				return "", errors.New("negative count")
+			}
+			return strings.Repeat(s, n), nil
+		},
+		"sum": func(xs ...int64) int64 {
+			var total int64
+			for _, x := range xs {
+				total += x
+			}
+			return total
+		},
+		"joined":   func(sep string, parts []string) string { return strings.Join(parts, sep) },
+		"not":      func(b bool) bool { return !b },
+		"small":    func(i int8) int8 { return i },
+		"big":      func(i *big.Int) *big.Int { return i.Mul(i, i) },
+		"kind":     func(o object.Object) string { return string(o.Type()) },
+		"key":      func(k object.Hashable) string { return k.Inspect() },
+		"text":     func(s *object.String) string { return s.Value },
+		"noop":     func() {},
+		"fail":     func() error { return errors.New("failed") },
+		"max":      func() uint64 { return 1<<64 - 1 },
+		"unsigned": func(u uint) uint { return u },
+		"crash":    func() int { panic("boom") },
+	}
+	for name, fn := range funcs {
+		if err := interp.RegisterFunc(name, "", fn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+		err   string
+	}{
+		{name: "value and error", input: `repeat("ab", 3)`, exp: "ababab"},
+		{name: "go error", input: `repeat("ab", -1)`, err: "negative count at 1:7"},
+		{name: "type error", input: `repeat(1, 3)`, err: "argument 1 to `repeat` must be STRING, got=INTEGER at 1:7"},
+		{name: "arity", input: `repeat("ab")`, err: "wrong number of arguments. got=1, want=2 at 1:7"},
+		{name: "variadic", input: `sum(1, 2, 3)`, exp: "6"},
+		{name: "variadic empty", input: `sum()`, exp: "0"},
+		{name: "slice", input: `joined("-", ["a", "b"])`, exp: "a-b"},
+		{name: "slice element", input: `joined("-", ["a", 1])`, err: "argument 2 to `joined` must be ARRAY of STRING, got=ARRAY at 1:7"},
+		{name: "bool", input: `not(true)`, exp: "false"},
+		{name: "overflow", input: `small(1000)`, err: "argument 1 to `small` must be INTEGER, got=INTEGER at 1:6"},
+		{name: "big", input: `big(9223372036854775807 * 2)`, exp: "340282366920938463389587631136930004996"},
+		{name: "object", input: `kind({})`, exp: "HASH"},
+		{name: "object null", input: `kind(noop())`, exp: "NULL"},
+		{name: "interface", input: `key(1)`, exp: "1"},
+		{name: "interface error", input: `key([])`, err: "argument 1 to `key` must be Hashable, got=ARRAY at 1:4"},
+		{name: "object type error", input: `text(1)`, err: "argument 1 to `text` must be STRING, got=INTEGER at 1:5"},
+		{name: "null argument", input: `repeat(noop(), 1)`, err: "argument 1 to `repeat` must be STRING, got=NULL at 1:7"},
+		{name: "panic", input: `crash()`, err: "panic in `crash`: boom at 1:6"},
+		{name: "negative uint", input: `unsigned(-1)`, err: "argument 1 to `unsigned` must be INTEGER, got=INTEGER at 1:9"},
+		{name: "no result", input: `noop()`, exp: "null"},
+		{name: "error only", input: `fail()`, err: "failed at 1:5"},
+		{name: "uint", input: `max()`, exp: "18446744073709551615"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRun(t, interp, tt.input, tt.exp, tt.err)
+		})
+	}
+}
+
+func TestWrapFuncErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   interface{}
+		err  string
+	}{
+		{"not a function", 1, "builtin f: int is not a function"},
+		{"parameter", func(map[string]int) {}, "builtin f: unsupported parameter type map[string]int"},
+		{"result", func() chan int { return nil }, "builtin f: unsupported result type chan int"},
+		{"second result", func() (int, int) { return 0, 0 }, "builtin f: second result must be error, got int"},
+		{"too many results", func() (int, int, error) { return 0, 0, nil }, "builtin f: too many results"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := WrapFunc("f", "", tt.fn)
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("want %q, but %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func testRun(t *testing.T, interp *Interpreter, input, exp, expErr string) {
+	t.Helper()
+
+	result, err := interp.Run(context.Background(), input)
+	if expErr != "" {
+		if err == nil {
+			t.Fatalf("want error %q, but got %v", expErr, result)
+		}
+		if err.Error() != expErr {
+			t.Errorf("want %q, but %q", expErr, err.Error())
+		}
+		return
+	}
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.Inspect() != exp {
+		t.Errorf("want %q, but %q", exp, result.Inspect())
+	}
+}
+
+func TestBuiltinCallbacks(t *testing.T) {
+	interp := NewInterpreter()
+	interp.Register("apply_twice", 2, "", func(ctx object.CallContext, args ...object.Object) object.Object {
+		result := ctx.Apply(args[0], args[1])
+		if result.Type() == object.ERROR_OBJ {
+			return result
+		}
+		return ctx.Apply(args[0], result)
+	})
+	err := interp.RegisterFunc("count_if", "", func(ctx object.CallContext, fn object.Object, xs []int) (int, error) {
+		n := 0
+		for _, x := range xs {
+			result := ctx.Apply(fn, &object.Integer{Value: int64(x)})
+			if errObj, ok := result.(*object.Error); ok {
+				return 0, errors.New(errObj.Message)
+			}
+			if result == TRUE {
+				n++
+			}
+		}
+		return n, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+		err   string
+	}{
+		{name: "closure", input: `let n = 3; apply_twice(fn(x) { x * n }, 2)`, exp: "18"},
+		{name: "builtin", input: `apply_twice(rest, [1, 2, 3])`, exp: "[3]"},
+		{name: "wrapped", input: `count_if(fn(x) { x > 1 }, [1, 2, 3])`, exp: "2"},
+		{name: "arity", input: `apply_twice(fn(x, y) { x }, 1)`, err: "wrong number of arguments. got=1, want=2 at 1:12"},
+		{name: "error", input: `apply_twice(fn(x) { x / 0 }, 1)`, err: "division by zero at 1:23"},
+		{name: "not a function", input: `apply_twice(1, 1)`, err: "not a function INTEGER at 1:12"},
+		{name: "catch", input: `try { apply_twice(fn(x) { throw x }, 7) } catch (e) { e["value"] }`, exp: "7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRun(t, interp, tt.input, tt.exp, tt.err)
+		})
+	}
+}
+
+type testAccount struct {
+	Owner   string
+	Balance int64
+	Token   string
+}
+
+func (a *testAccount) Deposit(n int64) (int64, error) {
+	if n <= 0 {
+		return a.Balance, errors.New("deposit must be positive")
+	}
+	a.Balance += n
+	return a.Balance, nil
+}
+
+func (a *testAccount) Close() { a.Balance = 0 }
+
+func TestGoValue(t *testing.T) {
+	account := &testAccount{Owner: "ann", Balance: 10, Token: "secret"}
+
+	interp := NewInterpreter()
+	interp.Env().Set("account", object.NewGoValue(account, "Owner", "Balance", "Deposit"))
+
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+		err   string
+	}{
+		{name: "field", input: `account["Owner"]`, exp: "ann"},
+		{name: "method", input: `account["Deposit"](5)`, exp: "15"},
+		{name: "bound method", input: `let deposit = account["Deposit"]; deposit(1); account["Balance"]`, exp: "16"},
+		{name: "method error", input: `account["Deposit"](-1)`, err: "deposit must be positive at 1:19"},
+		{name: "argument", input: `account["Deposit"]("1")`, err: "argument 1 to `*evaluator.testAccount.Deposit` must be INTEGER, got=STRING at 1:19"},
+		{name: "hidden field", input: `account["Token"]`, err: "go value *evaluator.testAccount has no accessible member Token at 1:8"},
+		{name: "hidden method", input: `account["Close"]`, err: "go value *evaluator.testAccount has no accessible member Close at 1:8"},
+		{name: "inspect", input: `account`, exp: "go value *evaluator.testAccount"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRun(t, interp, tt.input, tt.exp, tt.err)
+		})
+	}
+
+	if account.Balance != 16 {
+		t.Errorf("want balance %d, but %d", 16, account.Balance)
+	}
+}
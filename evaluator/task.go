@@ -0,0 +1,505 @@
+package evaluator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/smith-30/go-monkey/object"
+)
+
+// Tasks run Monkey functions on goroutines and talk through channels.
+
+// scheduler keeps track of the tasks of a single evaluation. When every
+// task that has not finished is blocked without a timeout, none of them
+// can make progress and all of them fail with a deadlock error.
+//
+// All channel and task state of the evaluation is guarded by mu. Holding a
+// single lock lets a task that hands a value to a blocked one mark it
+// runnable before anyone else looks, so the deadlock check never sees a
+// task as blocked once it has been woken.
+type scheduler struct {
+	mu      sync.Mutex
+	running int              // tasks that have not finished, including the main one
+	blocked map[*waiter]bool // waits without a timeout
+}
+
+// waiter is a task blocked in send, receive, select or await.
+type waiter struct {
+	sched    *scheduler
+	selected []*Channel    // channels a receive or select waits on
+	value    object.Object // value to send, or the value received
+	from     int           // index of the channel a select received from
+	err      *object.Error
+	fired    bool // woken, timed out or stopped; it no longer waits
+	deadlock bool
+	ready    chan struct{}
+}
+
+// Task is the handle of a function started with spawn. It can only be
+// awaited by the evaluation that started it.
+type Task struct {
+	sched   *scheduler
+	done    bool
+	result  object.Object
+	waiters []*waiter
+}
+
+func (t *Task) Type() object.ObjectType { return object.TASK_OBJ }
+func (t *Task) Inspect() string         { return "task" }
+
+// Channel passes values between tasks. A channel without capacity hands
+// each value directly from the sender to a receiver. It can only be used by
+// the evaluation that created it.
+type Channel struct {
+	sched    *scheduler
+	capacity int
+	buf      []object.Object
+	closed   bool
+	recvq    []*waiter
+	sendq    []*waiter
+}
+
+func (c *Channel) Type() object.ObjectType { return object.CHANNEL_OBJ }
+func (c *Channel) Inspect() string         { return fmt.Sprintf("channel(%d)", c.capacity) }
+
+// evaluatorOf returns the evaluation a builtin was called from.
+func evaluatorOf(ctx object.CallContext) *Evaluator {
+	if c, ok := ctx.(callContext); ok {
+		return c.ev
+	}
+	return &Evaluator{}
+}
+
+func (ev *Evaluator) scheduler() *scheduler {
+	return ev.state().sched
+}
+
+// finish marks the main task of the evaluation as done. Tasks nobody
+// awaited and generators left suspended are stopped and waited for, so that
+// none of them outlives the evaluation, e.g. by writing to Stdout after Run
+// has returned.
+func (ev *Evaluator) finish() {
+	run := ev.run
+	if run == nil {
+		return
+	}
+	close(run.done)
+	if run.cancel != nil {
+		run.cancel()
+	}
+
+	sched := run.sched
+	sched.mu.Lock()
+	sched.exit()
+	sched.mu.Unlock()
+
+	run.goroutines.Wait()
+}
+
+func (s *scheduler) exit() {
+	s.running--
+	s.checkDeadlock()
+}
+
+func (s *scheduler) checkDeadlock() {
+	if s.running == 0 || len(s.blocked) != s.running {
+		return
+	}
+
+	for w := range s.blocked {
+		w.err = newError("deadlock: all tasks are blocked")
+		w.deadlock = true
+		w.wake()
+	}
+}
+
+func (w *waiter) wake() {
+	w.fired = true
+	delete(w.sched.blocked, w)
+	close(w.ready)
+}
+
+// wait blocks until w is woken, timeout passes or the evaluation stops.
+// A negative timeout waits forever. It must be called with the lock of the
+// scheduler held and releases it. It reports false on timeout.
+func (ev *Evaluator) wait(w *waiter, timeout time.Duration) (bool, *object.Error) {
+	if timeout < 0 {
+		w.sched.blocked[w] = true
+		w.sched.checkDeadlock()
+	}
+	w.sched.mu.Unlock()
+
+	var timer <-chan time.Time
+	if timeout >= 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+
+	var done <-chan struct{}
+	if ev.ctx != nil {
+		done = ev.ctx.Done()
+	}
+
+	select {
+	case <-w.ready:
+		return true, ev.waitError(w)
+	case <-timer:
+	case <-done:
+	}
+
+	w.sched.mu.Lock()
+	defer w.sched.mu.Unlock()
+
+	if w.fired {
+		// woken while giving up
+		return true, ev.waitError(w)
+	}
+	w.fired = true
+	delete(w.sched.blocked, w)
+
+	if timer == nil {
+		return false, newError("evaluation stopped: %s", ev.ctx.Err())
+	}
+	return false, nil
+}
+
+// waitError returns the error a woken waiter fails with.
+func (ev *Evaluator) waitError(w *waiter) *object.Error {
+	if w.deadlock && ev.ctx != nil && ev.ctx.Err() != nil {
+		// the other tasks were stopped, they did not block
+		return newError("evaluation stopped: %s", ev.ctx.Err())
+	}
+	return w.err
+}
+
+func (ev *Evaluator) newWaiter() *waiter {
+	return &waiter{sched: ev.scheduler(), ready: make(chan struct{})}
+}
+
+// pop removes the first waiter of q that still waits.
+func pop(q *[]*waiter) *waiter {
+	for len(*q) > 0 {
+		w := (*q)[0]
+		*q = (*q)[1:]
+		if !w.fired {
+			return w
+		}
+	}
+	return nil
+}
+
+// tryReceive takes a value from c if that does not block.
+// It must be called with the lock of the scheduler held.
+func (c *Channel) tryReceive() (object.Object, bool) {
+	if len(c.buf) > 0 {
+		v := c.buf[0]
+		c.buf = c.buf[1:]
+		if s := pop(&c.sendq); s != nil {
+			c.buf = append(c.buf, s.value)
+			s.wake()
+		}
+		return v, true
+	}
+
+	if s := pop(&c.sendq); s != nil {
+		v := s.value
+		s.wake()
+		return v, true
+	}
+
+	if c.closed {
+		return NULL, true
+	}
+
+	return nil, false
+}
+
+func (ev *Evaluator) spawn(fn object.Object, args []object.Object) object.Object {
+	run := ev.state()
+	sched := run.sched
+	task := &Task{sched: sched}
+
+	sched.mu.Lock()
+	// the main task is not counted
+	if max := ev.Limits.MaxTasks; max > 0 && sched.running > max {
+		sched.mu.Unlock()
+		return newError("task limit exceeded: %d", max)
+	}
+	sched.running++
+	run.goroutines.Add(1)
+	sched.mu.Unlock()
+
+	// the task evaluates on a copy that has a call stack of its own and
+	// shares the state of the evaluation
+	child := *ev
+	child.depth = 0
+
+	// calling through the interface keeps the builtin table, which
+	// refers to spawn, out of an initialization cycle with applyFunction
+	var ctx object.CallContext = callContext{&child}
+
+	go func() {
+		defer run.goroutines.Done()
+
+		result := ctx.Apply(fn, args...)
+		if result == nil {
+			result = NULL
+		}
+
+		sched.mu.Lock()
+		defer sched.mu.Unlock()
+
+		task.done = true
+		task.result = result
+		for _, w := range task.waiters {
+			if !w.fired {
+				w.value = result
+				w.wake()
+			}
+		}
+		task.waiters = nil
+		sched.exit()
+	}()
+
+	return task
+}
+
+func (ev *Evaluator) await(task *Task) object.Object {
+	sched := ev.scheduler()
+	if task.sched != sched {
+		return newError("task belongs to another evaluation")
+	}
+
+	sched.mu.Lock()
+	if task.done {
+		sched.mu.Unlock()
+		return awaitResult(task.result)
+	}
+
+	w := ev.newWaiter()
+	task.waiters = append(task.waiters, w)
+	if _, err := ev.wait(w, -1); err != nil {
+		return err
+	}
+
+	return awaitResult(w.value)
+}
+
+// awaitResult gives every caller of await a copy of an error to add its
+// own position and stack to.
+func awaitResult(result object.Object) object.Object {
+	if err, ok := result.(*object.Error); ok && isError(err) {
+		copied := *err
+		copied.Stack = append([]object.Frame(nil), err.Stack...)
+		return &copied
+	}
+	return result
+}
+
+func (ev *Evaluator) send(c *Channel, v object.Object) object.Object {
+	sched := ev.scheduler()
+	if c.sched != sched {
+		return newError("channel belongs to another evaluation")
+	}
+
+	sched.mu.Lock()
+	if c.closed {
+		sched.mu.Unlock()
+		return newError("send on closed channel")
+	}
+
+	if r := pop(&c.recvq); r != nil {
+		r.value = v
+		r.from = indexOf(r, c)
+		r.wake()
+		sched.mu.Unlock()
+		return NULL
+	}
+
+	if len(c.buf) < c.capacity {
+		c.buf = append(c.buf, v)
+		sched.mu.Unlock()
+		return NULL
+	}
+
+	w := ev.newWaiter()
+	w.value = v
+	c.sendq = append(c.sendq, w)
+	if _, err := ev.wait(w, -1); err != nil {
+		return err
+	}
+
+	return NULL
+}
+
+// selectChannels receives from the first of chans that has a value. A
+// negative timeout waits forever. It reports false on timeout.
+func (ev *Evaluator) selectChannels(chans []*Channel, timeout time.Duration) (int, object.Object, bool, *object.Error) {
+	sched := ev.scheduler()
+	for _, c := range chans {
+		if c.sched != sched {
+			return 0, nil, false, newError("channel belongs to another evaluation")
+		}
+	}
+
+	sched.mu.Lock()
+	for i, c := range chans {
+		if v, ok := c.tryReceive(); ok {
+			sched.mu.Unlock()
+			return i, v, true, nil
+		}
+	}
+
+	w := ev.newWaiter()
+	w.selected = chans
+	for _, c := range chans {
+		c.recvq = append(c.recvq, w)
+	}
+
+	ok, err := ev.wait(w, timeout)
+	return w.from, w.value, ok, err
+}
+
+// indexOf returns the index of c among the channels w waits on.
+func indexOf(w *waiter, c *Channel) int {
+	for i, s := range w.selected {
+		if s == c {
+			return i
+		}
+	}
+	return 0
+}
+
+func (ev *Evaluator) closeChannel(c *Channel) object.Object {
+	sched := ev.scheduler()
+	if c.sched != sched {
+		return newError("channel belongs to another evaluation")
+	}
+
+	sched.mu.Lock()
+	defer sched.mu.Unlock()
+
+	if c.closed {
+		return newError("close of closed channel")
+	}
+	c.closed = true
+
+	for r := pop(&c.recvq); r != nil; r = pop(&c.recvq) {
+		r.value = NULL
+		r.from = indexOf(r, c)
+		r.wake()
+	}
+	for s := pop(&c.sendq); s != nil; s = pop(&c.sendq) {
+		s.err = newError("send on closed channel")
+		s.wake()
+	}
+
+	return NULL
+}
+
+func builtinSpawn(ctx object.CallContext, args ...object.Object) object.Object {
+	if len(args) < 1 {
+		return newError("wrong number of arguments. got=%d, want at least 1", len(args))
+	}
+	if !isCallable(args[0]) {
+		return newError("first argument to `spawn` must be Function, got=%s", args[0].Type())
+	}
+
+	return evaluatorOf(ctx).spawn(args[0], args[1:])
+}
+
+func builtinAwait(ctx object.CallContext, args ...object.Object) object.Object {
+	task, ok := args[0].(*Task)
+	if !ok {
+		return newError("argument to `await` must be Task, got=%s", args[0].Type())
+	}
+
+	return evaluatorOf(ctx).await(task)
+}
+
+func builtinChannel(ctx object.CallContext, args ...object.Object) object.Object {
+	sched := evaluatorOf(ctx).scheduler()
+	switch len(args) {
+	case 0:
+		return &Channel{sched: sched}
+	case 1:
+		n, ok := args[0].(*object.Integer)
+		if !ok || n.Value < 0 {
+			return newError("argument to `channel` must be a non-negative Integer, got=%s", args[0].Inspect())
+		}
+		return &Channel{sched: sched, capacity: int(n.Value)}
+	default:
+		return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+	}
+}
+
+func builtinSend(ctx object.CallContext, args ...object.Object) object.Object {
+	c, ok := args[0].(*Channel)
+	if !ok {
+		return newError("first argument to `send` must be Channel, got=%s", args[0].Type())
+	}
+
+	return evaluatorOf(ctx).send(c, args[1])
+}
+
+func builtinReceive(ctx object.CallContext, args ...object.Object) object.Object {
+	c, ok := args[0].(*Channel)
+	if !ok {
+		return newError("argument to `receive` must be Channel, got=%s", args[0].Type())
+	}
+
+	_, v, _, err := evaluatorOf(ctx).selectChannels([]*Channel{c}, -1)
+	if err != nil {
+		return err
+	}
+	return v
+}
+
+func builtinClose(ctx object.CallContext, args ...object.Object) object.Object {
+	c, ok := args[0].(*Channel)
+	if !ok {
+		return newError("argument to `close` must be Channel, got=%s", args[0].Type())
+	}
+
+	return evaluatorOf(ctx).closeChannel(c)
+}
+
+func builtinSelect(ctx object.CallContext, args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok || len(arr.Elements) == 0 {
+		return newError("first argument to `select` must be a non-empty Array of Channel, got=%s", args[0].Inspect())
+	}
+	chans := make([]*Channel, len(arr.Elements))
+	for i, e := range arr.Elements {
+		c, ok := e.(*Channel)
+		if !ok {
+			return newError("first argument to `select` must be a non-empty Array of Channel, got=%s", args[0].Inspect())
+		}
+		chans[i] = c
+	}
+
+	timeout := time.Duration(-1)
+	if len(args) == 2 {
+		ms, ok := args[1].(*object.Integer)
+		if !ok || ms.Value < 0 {
+			return newError("second argument to `select` must be a non-negative Integer, got=%s", args[1].Inspect())
+		}
+		timeout = time.Duration(ms.Value) * time.Millisecond
+	}
+
+	i, v, ok, err := evaluatorOf(ctx).selectChannels(chans, timeout)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return NULL
+	}
+
+	return &object.Array{Elements: []object.Object{&object.Integer{Value: int64(i)}, v}}
+}
@@ -0,0 +1,234 @@
+package evaluator
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTasks(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+		err   string
+	}{
+		{name: "await", input: `let t = spawn(fn(x) { x * 2 }, 21); await(t)`, exp: "42"},
+		{name: "await twice", input: `let t = spawn(fn() { 1 }); await(t) + await(t)`, exp: "2"},
+		{name: "fan out", input: `let ts = map([1, 2, 3], fn(x) { spawn(fn() { x * x }) }); map(ts, await)`, exp: "[1, 4, 9]"},
+		{name: "builtin", input: `await(spawn(len, "abc"))`, exp: "3"},
+		{name: "no value", input: `await(spawn(fn() { let a = 1; }))`, exp: "null"},
+		{name: "task error", input: `await(spawn(fn() { 1 / 0 }))`, err: "division by zero at 1:22"},
+		{name: "caught task error", input: `try { await(spawn(fn() { throw "x"; })) } catch (e) { e["value"] }`, exp: "x"},
+		{name: "spawn argument", input: `spawn(1)`, err: "first argument to `spawn` must be Function, got=INTEGER at 1:6"},
+		{name: "await argument", input: `await(1)`, err: "argument to `await` must be Task, got=INTEGER at 1:6"},
+		{name: "inspect", input: `[spawn(fn() { 1 }), channel(3)]`, exp: "[task, channel(3)]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRun(t, NewInterpreter(), tt.input, tt.exp, tt.err)
+		})
+	}
+}
+
+func TestTaskCancelUncatchable(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := NewInterpreter().Run(ctx, `
+let spin = fn() { select([channel()], 1); spin() };
+try { spin() } catch (e) { "caught" }`)
+
+	if err == nil || err.(*RuntimeError).Err.Message != "evaluation stopped: context deadline exceeded" {
+		t.Errorf("want cancellation error, but %v", err)
+	}
+}
+
+func TestChannels(t *testing.T) {
+	pipeline := `
+let ch = channel();
+let produce = fn(n) { if (n > 0) { send(ch, n); produce(n - 1) } else { close(ch) } };
+spawn(produce, 100);
+let consume = fn(acc) { let v = receive(ch); if (v) { consume(acc + v) } else { acc } };
+consume(0)`
+
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+		err   string
+	}{
+		{name: "pipeline", input: pipeline, exp: "5050"},
+		{name: "buffered", input: `let ch = channel(2); send(ch, 1); send(ch, 2); [receive(ch), receive(ch)]`, exp: "[1, 2]"},
+		{name: "blocked sender", input: `let ch = channel(1); let t = spawn(fn() { send(ch, 1); send(ch, 2); "sent" }); [receive(ch), receive(ch), await(t)]`, exp: `[1, 2, "sent"]`},
+		{name: "closed", input: `let ch = channel(1); send(ch, 1); close(ch); [receive(ch), receive(ch)]`, exp: "[1, null]"},
+		{name: "close wakes receivers", input: `let ch = channel(); let t = spawn(receive, ch); close(ch); await(t)`, exp: "null"},
+		{name: "send on closed", input: `let ch = channel(); close(ch); send(ch, 1)`, err: "send on closed channel at 1:36"},
+		{name: "close closed", input: `let ch = channel(); close(ch); close(ch)`, err: "close of closed channel at 1:37"},
+		{name: "capacity", input: `channel(-1)`, err: "argument to `channel` must be a non-negative Integer, got=-1 at 1:8"},
+		{name: "receive argument", input: `receive([])`, err: "argument to `receive` must be Channel, got=ARRAY at 1:8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRun(t, NewInterpreter(), tt.input, tt.exp, tt.err)
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+		err   string
+	}{
+		{name: "ready", input: `let a = channel(1); let b = channel(1); send(b, "x"); select([a, b])`, exp: `[1, "x"]`},
+		{name: "wait", input: `let a = channel(); let b = channel(); spawn(send, b, "y"); select([a, b])`, exp: `[1, "y"]`},
+		{name: "timeout", input: `select([channel()], 10)`, exp: "null"},
+		{name: "poll", input: `let a = channel(1); send(a, 1); [select([a], 0), select([a], 0)]`, exp: "[[0, 1], null]"},
+		{name: "closed", input: `let a = channel(); let b = channel(); close(b); select([a, b])`, exp: "[1, null]"},
+		{name: "no channels", input: `select([])`, err: "first argument to `select` must be a non-empty Array of Channel, got=[] at 1:7"},
+		{name: "timeout argument", input: `select([channel()], "1")`, err: "second argument to `select` must be a non-negative Integer, got=1 at 1:7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRun(t, NewInterpreter(), tt.input, tt.exp, tt.err)
+		})
+	}
+}
+
+func TestDeadlock(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{name: "main only", input: `receive(channel())`, err: "deadlock: all tasks are blocked at 1:8"},
+		{name: "unbuffered send", input: `send(channel(), 1)`, err: "deadlock: all tasks are blocked at 1:5"},
+		{name: "task finished", input: `let ch = channel(); spawn(fn() { receive(ch) }); send(ch, 1); receive(ch)`, err: "deadlock: all tasks are blocked at 1:70"},
+		{name: "await blocked task", input: `let ch = channel(); await(spawn(fn() { receive(ch) }))`, err: "deadlock: all tasks are blocked at 1:26"},
+		{name: "cycle", input: `let a = channel(); let b = channel(); spawn(fn() { receive(a); send(b, 1) }); receive(b); send(a, 1)`, err: "deadlock: all tasks are blocked at 1:86"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRun(t, NewInterpreter(), tt.input, "", tt.err)
+		})
+	}
+
+	t.Run("leaked task", func(t *testing.T) {
+		// a task still waiting when the script ends is released with an error
+		testRun(t, NewInterpreter(), `let ch = channel(); let t = spawn(receive, ch); t`, "task", "")
+	})
+}
+
+func TestTaskCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// the task keeps polling, so the blocked main task is not a deadlock
+	_, err := NewInterpreter().Run(ctx, `
+let ch = channel();
+let spin = fn() { select([channel()], 1); spin() };
+spawn(spin);
+receive(ch)`)
+
+	if err == nil || err.Error() != "evaluation stopped: context deadline exceeded at 5:8" {
+		t.Errorf("want cancellation error, but %v", err)
+	}
+}
+
+func TestForeignChannels(t *testing.T) {
+	// values created by one run cannot be used by the next one, nor by
+	// another interpreter, because they are locked by their own scheduler
+	interp := NewInterpreter()
+	if _, err := interp.Run(context.Background(), `let ch = channel(1); let t = spawn(fn() { 1 });`); err != nil {
+		t.Fatal(err)
+	}
+	other := NewInterpreter()
+	for _, name := range []string{"ch", "t"} {
+		v, _ := interp.Env().Get(name)
+		other.Env().Set(name, v)
+	}
+
+	tests := []struct {
+		name   string
+		interp *Interpreter
+		input  string
+		err    string
+	}{
+		{name: "send", interp: interp, input: `send(ch, 1)`, err: "channel belongs to another evaluation at 1:5"},
+		{name: "receive", interp: other, input: `receive(ch)`, err: "channel belongs to another evaluation at 1:8"},
+		{name: "select", interp: other, input: `select([channel(), ch], 0)`, err: "channel belongs to another evaluation at 1:7"},
+		{name: "close", interp: other, input: `close(ch)`, err: "channel belongs to another evaluation at 1:6"},
+		{name: "await", interp: interp, input: `await(t)`, err: "task belongs to another evaluation at 1:6"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRun(t, tt.interp, tt.input, "", tt.err)
+		})
+	}
+}
+
+// exclusiveWriter counts the lines written and the writes that overlap
+// another one.
+type exclusiveWriter struct {
+	writing, overlaps, lines int32
+}
+
+func (w *exclusiveWriter) Write(p []byte) (int, error) {
+	if !atomic.CompareAndSwapInt32(&w.writing, 0, 1) {
+		atomic.AddInt32(&w.overlaps, 1)
+		return len(p), nil
+	}
+	time.Sleep(10 * time.Microsecond)
+	atomic.AddInt32(&w.lines, int32(strings.Count(string(p), "\n")))
+	atomic.StoreInt32(&w.writing, 0)
+	return len(p), nil
+}
+
+func TestTaskOutput(t *testing.T) {
+	out := &exclusiveWriter{}
+	interp := NewInterpreter()
+	interp.Stdout = out
+	interp.Stderr = out
+
+	_, err := interp.Run(context.Background(), `
+let say = fn(n) { if (n > 0) { puts(n); say(n - 1) } };
+let ts = collect(map(range(8), fn(i) { spawn(say, 50) }));
+map(ts, await)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// tasks take turns writing to the writer of the host
+	if out.overlaps != 0 {
+		t.Errorf("want no overlapping writes, but %d", out.overlaps)
+	}
+	if out.lines != 400 {
+		t.Errorf("want %d lines, but %d", 400, out.lines)
+	}
+}
+
+func TestTaskOutlivingRun(t *testing.T) {
+	var out bytes.Buffer
+	interp := NewInterpreter()
+	interp.Stdout = &out
+
+	// nobody awaits the task, which would keep printing
+	_, err := interp.Run(context.Background(), `
+let late = fn(n) { if (n > 0) { select([channel()], 5); puts("late"); late(n - 1) } };
+spawn(late, 100);
+1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	written := out.Len()
+	time.Sleep(50 * time.Millisecond)
+	if out.Len() != written {
+		t.Errorf("want no output after Run returned, but %q", out.String()[written:])
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/smith-30/go-monkey/format"
+)
+
+// runFmt implements `monkey fmt [-w] files...`, which formats the files, or
+// the standard input if none are given, and returns the exit status.
+func runFmt(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	write := flags.Bool("w", false, "write the result to the file instead of the standard output")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: monkey fmt [-w] [files...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(stderr, "monkey fmt: cannot use -w with the standard input")
+			return 2
+		}
+
+		src, err := ioutil.ReadAll(stdin)
+		if err == nil {
+			err = formatTo(stdout, src)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "monkey fmt: %s\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	status := 0
+	for _, name := range flags.Args() {
+		if err := formatFile(name, *write, stdout); err != nil {
+			fmt.Fprintf(stderr, "monkey fmt: %s: %s\n", name, err)
+			status = 1
+		}
+	}
+	return status
+}
+
+func formatTo(w io.Writer, src []byte) error {
+	out, err := format.Source(src)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+func formatFile(name string, write bool, stdout io.Writer) error {
+	src, err := ioutil.ReadFile(name)
+	if err != nil {
+		return err
+	}
+
+	if !write {
+		return formatTo(stdout, src)
+	}
+
+	out, err := format.Source(src)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(src, out) {
+		return nil
+	}
+	return ioutil.WriteFile(name, out, 0644)
+}
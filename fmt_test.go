@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunFmt(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.mk")
+	if err := ioutil.WriteFile(file, []byte("let x=1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.mk")
+
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		status int
+		stdout string
+		stderr string // prefix of the standard error
+	}{
+		{name: "stdin", stdin: "let x=1", stdout: "let x = 1;\n"},
+		{name: "file", args: []string{file}, stdout: "let x = 1;\n"},
+		{name: "write stdin", args: []string{"-w"}, stdin: "let x=1", status: 2, stderr: "monkey fmt: cannot use -w with the standard input\n"},
+		{name: "parse error", stdin: "let =", status: 1, stderr: "monkey fmt: parse error: "},
+		{name: "missing file", args: []string{missing}, status: 1, stderr: "monkey fmt: " + missing + ": "},
+		{name: "unknown flag", args: []string{"-x"}, status: 2, stderr: "flag provided but not defined: -x\nusage: monkey fmt [-w] [files...]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			status := runFmt(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+
+			if status != tt.status {
+				t.Errorf("want status %d, but %d", tt.status, status)
+			}
+			if stdout.String() != tt.stdout {
+				t.Errorf("want %q, but %q", tt.stdout, stdout.String())
+			}
+			if !strings.HasPrefix(stderr.String(), tt.stderr) || (tt.stderr == "") != (stderr.Len() == 0) {
+				t.Errorf("want %q, but %q", tt.stderr, stderr.String())
+			}
+		})
+	}
+
+	t.Run("write", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		if status := runFmt([]string{"-w", file}, nil, &stdout, &stderr); status != 0 {
+			t.Fatalf("want status 0, but %d: %s", status, stderr.String())
+		}
+		if stdout.Len() != 0 {
+			t.Errorf("want no output, but %q", stdout.String())
+		}
+
+		got, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != "let x = 1;\n" {
+			t.Errorf("want %q, but %q", "let x = 1;\n", got)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+// Package format implements the canonical formatting of Monkey source code.
+//
+// The format is fixed: blocks are indented with tabs and every statement
+// ends with a semicolon. Parentheses are only kept where the precedence
+// of the operators needs them. Argument lists, array literals and hash
+// literals that do not fit in Width columns are broken into one element
+// per line. Comments and single blank lines between statements are kept.
+//
+// Formatting formatted source gives the same source.
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/smith-30/go-monkey/ast"
+	"github.com/smith-30/go-monkey/lexer"
+	"github.com/smith-30/go-monkey/parser"
+)
+
+// Width is the number of columns long lists are broken at. A tab counts as
+// TabWidth columns.
+const (
+	Width    = 80
+	TabWidth = 4
+)
+
+// Source formats Monkey source code. The source must parse without errors.
+func Source(src []byte) ([]byte, error) {
+	p := parser.New(lexer.New(string(src)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, fmt.Errorf("parse error: %s", strings.Join(p.Errors(), "; "))
+	}
+
+	var buf bytes.Buffer
+	if err := Node(&buf, program); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Node writes the canonical form of node to w. The comments of a program
+// are written along with it; other nodes are written without comments.
+func Node(w io.Writer, node ast.Node) error {
+	p := &printer{}
+
+	switch n := node.(type) {
+	case *ast.Program:
+		p.comments = n.Comments
+		p.program(n)
+	case *ast.BlockStatement:
+		p.block(n)
+	case ast.Statement:
+		p.statement(n)
+	case ast.Expression:
+		p.expr(n, parser.LOWEST)
+	default:
+		return fmt.Errorf("format: unsupported node %T", node)
+	}
+
+	_, err := w.Write(p.out.Bytes())
+	return err
+}
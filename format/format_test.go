@@ -0,0 +1,262 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/smith-30/go-monkey/ast"
+	"github.com/smith-30/go-monkey/lexer"
+	"github.com/smith-30/go-monkey/parser"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+	}{
+		{
+			name:  "statements",
+			input: `let x = 5; return x; throw "e"; x`,
+			exp: `let x = 5;
+return x;
+throw "e";
+x;
+`,
+		},
+		{
+			name:  "parentheses",
+			input: `(1 + 2) * 3; 1 + (2 * 3); a - (b - c); (a - b) - c; -(a + b); (-a)[0]; -(a[0]); !(a == b); (a < b) == (c > d); (fn(x) { x })(1)`,
+			exp: `(1 + 2) * 3;
+1 + 2 * 3;
+a - (b - c);
+a - b - c;
+-(a + b);
+(-a)[0];
+-a[0];
+!(a == b);
+a < b == c > d;
+fn(x) {
+	x;
+}(1);
+`,
+		},
+		{
+			name:  "blocks",
+			input: `if (x) { 1 } else { let y = 2; y }; fn() {}; try { a } catch (e) { b } finally { c }`,
+			exp: `if (x) {
+	1;
+} else {
+	let y = 2;
+	y;
+};
+fn() {};
+try {
+	a;
+} catch (e) {
+	b;
+} finally {
+	c;
+};
+`,
+		},
+		{
+			name:  "generators and macros",
+			input: `let g = fn() { yield 1; yield*[2, 3]; }; let m = macro(a, b) { quote(unquote(a) + unquote(b)) };`,
+			exp: `let g = fn() {
+	yield 1;
+	yield* [2, 3];
+};
+let m = macro(a, b) {
+	quote(unquote(a) + unquote(b));
+};
+`,
+		},
+		{
+			name: "blank lines",
+			input: `let a = 1;
+
+
+let b = 2;
+let c = fn() {
+
+	a;
+
+	b;
+};`,
+			exp: `let a = 1;
+
+let b = 2;
+let c = fn() {
+	a;
+
+	b;
+};
+`,
+		},
+		{
+			name: "comments",
+			input: `// header
+
+let a = 1; // one
+// before b
+let b = fn() { // opening
+	// first
+	a // inside
+	// last
+}; // closing
+// end`,
+			exp: `// header
+
+let a = 1; // one
+// before b
+let b = fn() { // opening
+	// first
+	a; // inside
+	// last
+}; // closing
+// end
+`,
+		},
+		{
+			name:  "comments inside expressions",
+			input: "let x = 1 + // plus\n 2;\nlet y = 3;",
+			exp: `let x = 1 + 2; // plus
+let y = 3;
+`,
+		},
+		{
+			name:  "long call",
+			input: `let total = reduce(numbers, 0, fn(accumulator, number) { accumulator + number }, initial_value, more);`,
+			exp: `let total = reduce(
+	numbers,
+	0,
+	fn(accumulator, number) {
+		accumulator + number;
+	},
+	initial_value,
+	more
+);
+`,
+		},
+		{
+			name:  "short call with function",
+			input: `map([1,2,3], fn(x) { x * 2 })`,
+			exp: `map([1, 2, 3], fn(x) {
+	x * 2;
+});
+`,
+		},
+		{
+			name: "hash",
+			input: `let h = {"name": "monkey", "age": 1};
+let config = {"host": "localhost", "port": 8080, "user": "admin", "password": "secret"};
+let c = {
+	// address
+	"host": "localhost", // default
+	"port": 8080
+};`,
+			exp: `let h = {"name": "monkey", "age": 1};
+let config = {
+	"host": "localhost",
+	"port": 8080,
+	"user": "admin",
+	"password": "secret"
+};
+let c = {
+	// address
+	"host": "localhost", // default
+	"port": 8080
+};
+`,
+		},
+		{
+			name:  "empty",
+			input: "",
+			exp:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Source([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(out) != tt.exp {
+				t.Errorf("want\n%s\nbut\n%s", tt.exp, out)
+			}
+
+			// formatting is idempotent
+			again, err := Source(out)
+			if err != nil {
+				t.Fatalf("formatted source does not parse: %s", err)
+			}
+			if !bytes.Equal(again, out) {
+				t.Errorf("formatting again changed the source:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestSourceParseError(t *testing.T) {
+	_, err := Source([]byte(`let = 1;`))
+	if err == nil {
+		t.Fatalf("want error, but got none")
+	}
+	if !strings.HasPrefix(err.Error(), "parse error: ") {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestSourceKeepsMeaning(t *testing.T) {
+	input := `let f = fn(a, b) { if (a > b) { a - b * 2 } else { -(a + b) } }; [f(1, 2), f(5, 1), !(1 == 2), (1 + 2) * 3, {"k": [1, 2][1]}["k"]]`
+
+	out, err := Source([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got, exp := parse(t, string(out)).String(), parse(t, input).String(); got != exp {
+		t.Errorf("formatting changed the program:\nwant %s\nbut  %s", exp, got)
+	}
+}
+
+func TestNode(t *testing.T) {
+	program := parse(t, `let add = fn(a, b) { a + b };`)
+	let := program.Statements[0].(*ast.LetStatement)
+
+	tests := []struct {
+		name string
+		node ast.Node
+		exp  string
+	}{
+		{"statement", let, "let add = fn(a, b) {\n\ta + b;\n};"},
+		{"expression", let.Value.(*ast.FunctionLiteral).Body.Statements[0].(*ast.ExpressionStatement).Expression, "a + b"},
+		{"block", let.Value.(*ast.FunctionLiteral).Body, "{\n\ta + b;\n}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Node(&buf, tt.node); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if buf.String() != tt.exp {
+				t.Errorf("want %q, but %q", tt.exp, buf.String())
+			}
+		})
+	}
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	t.Helper()
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parse errors: %v", p.Errors())
+	}
+	return program
+}
@@ -0,0 +1,462 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/smith-30/go-monkey/ast"
+	"github.com/smith-30/go-monkey/parser"
+	"github.com/smith-30/go-monkey/token"
+)
+
+// atom is the precedence of expressions that never need parentheses.
+const atom = parser.INDEX + 1
+
+type printer struct {
+	out bytes.Buffer
+
+	indent        int  // number of tabs at the start of a line
+	pendingIndent bool // the indentation of the current line is not written yet
+	col           int  // column the next character is written at
+
+	comments []*ast.Comment
+	next     int // index of the first comment not printed yet
+
+	line    int  // source line of the last statement or comment printed
+	noBreak bool // lists are kept on one line, used to measure them
+}
+
+func (p *printer) print(s string) {
+	if s == "" {
+		return
+	}
+	if p.pendingIndent {
+		p.out.WriteString(strings.Repeat("\t", p.indent))
+		p.col = p.indent * TabWidth
+		p.pendingIndent = false
+	}
+
+	p.out.WriteString(s)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		p.col = utf8.RuneCountInString(s[i+1:])
+	} else {
+		p.col += utf8.RuneCountInString(s)
+	}
+}
+
+// newline ends the current line. The indentation of the next line is
+// written with its first character, so blank lines stay empty.
+func (p *printer) newline() {
+	p.out.WriteByte('\n')
+	p.pendingIndent = true
+	p.col = 0
+}
+
+func (p *printer) column() int {
+	if p.pendingIndent {
+		return p.indent * TabWidth
+	}
+	return p.col
+}
+
+// fits reports whether the first line written by render fits in Width
+// when written at the current column.
+func (p *printer) fits(render func(q *printer)) bool {
+	if p.noBreak {
+		return true
+	}
+
+	q := &printer{noBreak: true}
+	render(q)
+
+	s := q.out.String()
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return p.column()+utf8.RuneCountInString(s) <= Width
+}
+
+// multiline reports whether an element is written on several lines.
+func (p *printer) multiline(elems []element) bool {
+	if p.noBreak {
+		return false
+	}
+
+	for _, e := range elems {
+		q := &printer{noBreak: true}
+		e.print(q)
+		if bytes.IndexByte(q.out.Bytes(), '\n') >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// comments
+
+// before reports whether pos comes before end. Every position comes before
+// an invalid end, which stands for the end of the source.
+func before(pos, end token.Position) bool {
+	if !end.IsValid() {
+		return true
+	}
+	return pos.Line < end.Line || pos.Line == end.Line && pos.Column < end.Column
+}
+
+func (p *printer) pending() *ast.Comment {
+	if p.next < len(p.comments) {
+		return p.comments[p.next]
+	}
+	return nil
+}
+
+// hasComments reports whether a comment not printed yet lies between start
+// and end.
+func (p *printer) hasComments(start, end token.Position) bool {
+	for _, c := range p.comments[p.next:] {
+		if !before(c.Pos(), end) {
+			return false
+		}
+		if before(start, c.Pos()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *printer) comment(c *ast.Comment) {
+	p.print(strings.TrimRight(c.Token.Literal, " \t\r"))
+	if c.Pos().Line > p.line {
+		p.line = c.Pos().Line
+	}
+	p.next++
+}
+
+// trailing prints the trailing comments before end after the code on the
+// current line. The caller must end the line.
+func (p *printer) trailing(end token.Position) {
+	for first := true; ; first = false {
+		c := p.pending()
+		if c == nil || !c.Trailing || !before(c.Pos(), end) {
+			return
+		}
+
+		if first {
+			p.print(" ")
+		} else {
+			p.newline()
+		}
+		p.comment(c)
+	}
+}
+
+// leading prints the comments before end on lines of their own. first
+// tells whether nothing of the enclosing list has been printed yet.
+func (p *printer) leading(end token.Position, first *bool) {
+	for c := p.pending(); c != nil && before(c.Pos(), end); c = p.pending() {
+		p.linebreak(c.Pos().Line, *first)
+		p.comment(c)
+		*first = false
+	}
+}
+
+// linebreak starts the line of a statement or comment found at the given
+// source line, keeping a blank line that separates it from the previous one.
+func (p *printer) linebreak(line int, first bool) {
+	if first && p.out.Len() == 0 {
+		return
+	}
+
+	p.newline()
+	if !first && line > p.line+1 {
+		p.newline()
+	}
+}
+
+// statements
+
+func (p *printer) program(program *ast.Program) {
+	p.statements(program.Statements, token.Position{})
+	if p.out.Len() > 0 {
+		p.newline()
+	}
+}
+
+// statements prints list, one statement per line, with the comments before
+// end. The caller ends the line of the last statement.
+func (p *printer) statements(list []ast.Statement, end token.Position) {
+	first := true
+	for i, s := range list {
+		p.leading(s.Pos(), &first)
+		p.linebreak(s.Pos().Line, first)
+		first = false
+
+		p.statement(s)
+		p.line = lastLine(s)
+
+		next := end
+		if i+1 < len(list) {
+			next = list[i+1].Pos()
+		}
+		p.trailing(next)
+	}
+	p.leading(end, &first)
+}
+
+func (p *printer) statement(s ast.Statement) {
+	switch s := s.(type) {
+	case *ast.LetStatement:
+		p.print("let " + s.Name.Value + " = ")
+		p.expr(s.Value, parser.LOWEST)
+	case *ast.ReturnStatement:
+		p.print("return ")
+		p.expr(s.ReturnValue, parser.LOWEST)
+	case *ast.ThrowStatement:
+		p.print("throw ")
+		p.expr(s.Value, parser.LOWEST)
+	case *ast.YieldStatement:
+		if s.Delegate {
+			p.print("yield* ")
+		} else {
+			p.print("yield ")
+		}
+		p.expr(s.Value, parser.LOWEST)
+	case *ast.ExpressionStatement:
+		p.expr(s.Expression, parser.LOWEST)
+	}
+	p.print(";")
+}
+
+func (p *printer) block(b *ast.BlockStatement) {
+	p.print("{")
+	if len(b.Statements) == 0 && !p.hasComments(token.Position{}, b.Rbrace) {
+		p.print("}")
+		return
+	}
+
+	end := b.Rbrace
+	if len(b.Statements) > 0 {
+		end = b.Statements[0].Pos()
+	}
+	p.trailing(end)
+
+	p.indent++
+	p.statements(b.Statements, b.Rbrace)
+	p.indent--
+
+	p.newline()
+	p.print("}")
+}
+
+// expressions
+
+func precedence(e ast.Expression) int {
+	switch e := e.(type) {
+	case *ast.InfixExpression:
+		switch e.Operator {
+		case "==", "!=":
+			return parser.EQUALS
+		case "<", ">":
+			return parser.LESSGREATER
+		case "+", "-":
+			return parser.SUM
+		default:
+			return parser.PRODUCT
+		}
+	case *ast.PrefixExpression:
+		return parser.PREFIX
+	case *ast.CallExpression, *ast.IndexExpression:
+		return parser.CALL
+	default:
+		return atom
+	}
+}
+
+// expr prints e, in parentheses if its precedence is lower than prec.
+func (p *printer) expr(e ast.Expression, prec int) {
+	if precedence(e) < prec {
+		p.print("(")
+		defer p.print(")")
+	}
+
+	switch e := e.(type) {
+	case *ast.Identifier:
+		p.print(e.Value)
+	case *ast.IntegerLiteral:
+		p.print(e.Token.Literal)
+	case *ast.StringLiteral:
+		p.print(`"` + e.Value + `"`)
+	case *ast.Boolean:
+		p.print(e.Token.Literal)
+	case *ast.PrefixExpression:
+		p.print(e.Operator)
+		p.expr(e.Right, parser.PREFIX)
+	case *ast.InfixExpression:
+		prec := precedence(e)
+		p.expr(e.Left, prec)
+		p.print(" " + e.Operator + " ")
+		// operators are left associative
+		p.expr(e.Right, prec+1)
+	case *ast.IfExpression:
+		p.print("if (")
+		p.expr(e.Condition, parser.LOWEST)
+		p.print(") ")
+		p.block(e.Consequence)
+		if e.Alternative != nil {
+			p.print(" else ")
+			p.block(e.Alternative)
+		}
+	case *ast.TryExpression:
+		p.print("try ")
+		p.block(e.Block)
+		if e.Catch != nil {
+			p.print(" catch (" + e.Param.Value + ") ")
+			p.block(e.Catch)
+		}
+		if e.Finally != nil {
+			p.print(" finally ")
+			p.block(e.Finally)
+		}
+	case *ast.FunctionLiteral:
+		p.print("fn")
+		p.parameters(e.Parameters)
+		p.block(e.Body)
+	case *ast.MacroLiteral:
+		p.print("macro")
+		p.parameters(e.Parameters)
+		p.block(e.Body)
+	case *ast.CallExpression:
+		p.expr(e.Function, parser.CALL)
+		p.list("(", ")", e.Token.Pos, e.Rparen, e.Arguments)
+	case *ast.IndexExpression:
+		p.expr(e.Left, parser.CALL)
+		p.print("[")
+		p.expr(e.Index, parser.LOWEST)
+		p.print("]")
+	case *ast.ArrayLiteral:
+		p.list("[", "]", e.Token.Pos, e.Rbracket, e.Elements)
+	case *ast.HashLiteral:
+		p.hash(e)
+	}
+}
+
+func (p *printer) parameters(params []*ast.Identifier) {
+	names := make([]string, len(params))
+	for i, param := range params {
+		names[i] = param.Value
+	}
+	p.print("(" + strings.Join(names, ", ") + ") ")
+}
+
+// element is an element of a list, like an argument or a pair of a hash.
+type element struct {
+	pos   token.Position
+	last  int // last source line
+	print func(q *printer)
+}
+
+func (p *printer) list(open, close string, start, end token.Position, exprs []ast.Expression) {
+	elems := make([]element, len(exprs))
+	for i, e := range exprs {
+		e := e
+		elems[i] = element{
+			pos:   e.Pos(),
+			last:  lastLine(e),
+			print: func(q *printer) { q.expr(e, parser.LOWEST) },
+		}
+	}
+	p.elements(open, close, start, end, elems)
+}
+
+func (p *printer) hash(hl *ast.HashLiteral) {
+	elems := make([]element, len(hl.Pairs))
+	for i, pair := range hl.Pairs {
+		k, v := pair.Key, pair.Value
+		elems[i] = element{
+			pos:  k.Pos(),
+			last: lastLine(v),
+			print: func(q *printer) {
+				q.expr(k, parser.LOWEST)
+				q.print(": ")
+				q.expr(v, parser.LOWEST)
+			},
+		}
+	}
+	p.elements("{", "}", hl.Token.Pos, hl.Rbrace, elems)
+}
+
+// elements prints comma separated elements between open and close. They
+// are put on one line if it fits and no comment is in between, otherwise
+// each element gets a line of its own. Only the last element may span
+// several lines when they share one, like the function in map(xs, fn(x) {}).
+func (p *printer) elements(open, close string, start, end token.Position, elems []element) {
+	flat := func(q *printer) {
+		q.print(open)
+		for i, e := range elems {
+			if i > 0 {
+				q.print(", ")
+			}
+			e.print(q)
+		}
+		q.print(close)
+	}
+
+	if len(elems) == 0 || !p.hasComments(start, end) && p.fits(flat) && !p.multiline(elems[:len(elems)-1]) {
+		flat(p)
+		return
+	}
+
+	p.print(open)
+	p.trailing(elems[0].pos)
+	p.indent++
+
+	first := true
+	for i, e := range elems {
+		p.leading(e.pos, &first)
+		p.linebreak(e.pos.Line, first)
+		first = false
+
+		e.print(p)
+		p.line = e.last
+
+		next := end
+		if i+1 < len(elems) {
+			p.print(",")
+			next = elems[i+1].pos
+		}
+		p.trailing(next)
+	}
+	p.leading(end, &first)
+	p.indent--
+
+	p.newline()
+	p.print(close)
+}
+
+// lastLine returns the last source line of node.
+func lastLine(node ast.Node) int {
+	line := 0
+	ast.Inspect(node, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+
+		end := n.Pos()
+		switch n := n.(type) {
+		case *ast.BlockStatement:
+			end = n.Rbrace
+		case *ast.CallExpression:
+			end = n.Rparen
+		case *ast.ArrayLiteral:
+			end = n.Rbracket
+		case *ast.HashLiteral:
+			end = n.Rbrace
+		}
+		if end.Line > line {
+			line = end.Line
+		}
+		return true
+	})
+	return line
+}
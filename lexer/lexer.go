@@ -7,11 +7,24 @@ type Lexer struct {
 	position     int  // now position about input.(indicate now character)
 	readPosition int  // position to read from now.(next to the current character)
 	ch           byte // character currently being inspected
+	line         int  // line of the current character
+	column       int  // column of the current character
+
+	lastLine int // line the last token ended on
+	comments []Comment
+}
+
+// Comment is a line comment, from // to the end of the line. NextToken skips
+// comments; they are kept for tools like the formatter.
+type Comment struct {
+	Token    token.Token // token.COMMENT, the literal starts with //
+	Trailing bool        // a token precedes the comment on its line
 }
 
 func New(input string) *Lexer {
 	l := &Lexer{
 		input: input,
+		line:  1,
 	}
 	l.readChar()
 	return l
@@ -19,6 +32,12 @@ func New(input string) *Lexer {
 
 // Todo: parsable whole Unicode. This func have not supported all Unicode yet.
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line += 1
+		l.column = 0
+	}
+	l.column += 1
+
 	if l.readPosition >= len(l.input) {
 		// 0 corresponds to ASCII's NUL
 		l.ch = 0
@@ -33,6 +52,11 @@ func (l *Lexer) NextToken() token.Token {
 	var t token.Token
 
 	l.skipWhitespace()
+	for l.ch == '/' && l.peekChar() == '/' {
+		l.readComment()
+		l.skipWhitespace()
+	}
+	pos := token.Position{Line: l.line, Column: l.column}
 
 	switch l.ch {
 	case '=':
@@ -101,10 +125,14 @@ func (l *Lexer) NextToken() token.Token {
 		if isLetter(l.ch) {
 			t.Literal = l.readIdentifier()
 			t.Type = token.LookUpIdent(t.Literal)
+			t.Pos = pos
+			l.lastLine = l.line
 			return t
 		} else if isDigit(l.ch) {
 			t.Type = token.INT
 			t.Literal = l.readNumber()
+			t.Pos = pos
+			l.lastLine = l.line
 			return t
 		} else {
 			t = token.NewToken(token.ILLEGAL, l.ch)
@@ -112,9 +140,29 @@ func (l *Lexer) NextToken() token.Token {
 	}
 
 	l.readChar()
+	t.Pos = pos
+	l.lastLine = l.line
 	return t
 }
 
+// Comments returns the comments skipped so far, in source order.
+func (l *Lexer) Comments() []Comment {
+	return l.comments
+}
+
+func (l *Lexer) readComment() {
+	pos := token.Position{Line: l.line, Column: l.column}
+	position := l.position
+	for l.ch != '\n' && l.ch != 0 {
+		l.readChar()
+	}
+
+	l.comments = append(l.comments, Comment{
+		Token:    token.Token{Type: token.COMMENT, Literal: l.input[position:l.position], Pos: pos},
+		Trailing: l.lastLine == pos.Line,
+	})
+}
+
 func (l *Lexer) readIdentifier() string {
 	position := l.position
 	for isLetter(l.ch) {
@@ -157,7 +157,10 @@ if (5 < 10) {
 		t.Run(tt.name, func(t *testing.T) {
 			l := New(tt.fields.input)
 			for _, token := range tt.want {
-				if got := l.NextToken(); !reflect.DeepEqual(got, token) {
+				// positions are covered by TestLexer_Position
+				got := l.NextToken()
+				got.Pos = token.Pos
+				if !reflect.DeepEqual(got, token) {
 					t.Errorf("\nact  %#v\nwant %#v", got, token)
 					return
 				}
@@ -165,3 +168,59 @@ if (5 < 10) {
 		})
 	}
 }
+
+func TestLexer_Position(t *testing.T) {
+	input := `let x = 5;
+  x + "a
+b";
+y`
+	want := []token.Position{
+		{Line: 1, Column: 1},
+		{Line: 1, Column: 5},
+		{Line: 1, Column: 7},
+		{Line: 1, Column: 9},
+		{Line: 1, Column: 10},
+		{Line: 2, Column: 3},
+		{Line: 2, Column: 5},
+		{Line: 2, Column: 7},
+		{Line: 3, Column: 3},
+		{Line: 4, Column: 1},
+		{Line: 4, Column: 2},
+	}
+
+	l := New(input)
+	for i, pos := range want {
+		got := l.NextToken()
+		if got.Pos != pos {
+			t.Errorf("token %d (%q) has wrong position. got=%s, want=%s", i, got.Literal, got.Pos, pos)
+		}
+	}
+}
+
+func TestLexer_Comments(t *testing.T) {
+	input := `// leading
+let x = 5; // trailing
+x / 2 // last`
+
+	wantTypes := []token.TokenType{
+		token.LET, token.IDENT, token.ASSIGN, token.INT, token.SEMICOLON,
+		token.IDENT, token.SLASH, token.INT, token.EOF,
+	}
+
+	l := New(input)
+	for i, tt := range wantTypes {
+		got := l.NextToken()
+		if got.Type != tt {
+			t.Fatalf("token %d has wrong type. got=%q, want=%q", i, got.Type, tt)
+		}
+	}
+
+	want := []Comment{
+		{Token: token.Token{Type: token.COMMENT, Literal: "// leading", Pos: token.Position{Line: 1, Column: 1}}},
+		{Token: token.Token{Type: token.COMMENT, Literal: "// trailing", Pos: token.Position{Line: 2, Column: 12}}, Trailing: true},
+		{Token: token.Token{Type: token.COMMENT, Literal: "// last", Pos: token.Position{Line: 3, Column: 7}}, Trailing: true},
+	}
+	if got := l.Comments(); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong comments.\ngot=%+v\nwant=%+v", got, want)
+	}
+}
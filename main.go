@@ -9,6 +9,15 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "fmt":
+			os.Exit(runFmt(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+		case "ast":
+			os.Exit(runAST(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+		}
+	}
+
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
@@ -18,31 +27,3 @@ func main() {
 	fmt.Printf("Feel free to type in commands\n")
 	repl.Start(os.Stdin, os.Stdout)
 }
-
-// let map = fn(arr, f) {
-// 	let iter = fn(arr, accumlated) {
-// 		if (len(arr) == 0) {
-// 			accumlated
-// 		} else {
-// 			iter(rest(arr), push(accumlated, f(first(arr))))
-// 		}
-// 	};
-
-// 	iter(arr, []);
-// }
-
-// let reduce = fn(arr, initial, f) {
-// 	let iter = fn(arr, result) {
-// 		if (len(arr) == 0) {
-// 			result
-// 		} else {
-// 			iter(rest(arr), f(result, first(arr)))
-// 		}
-// 	};
-
-// 	iter(arr, initial);
-// }
-
-// let sum = fn(arr) {
-// 	reduce(arr, 0, fn(initial, el) {initial + el});
-// };
@@ -0,0 +1,429 @@
+package object
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var (
+	objectType = reflect.TypeOf((*Object)(nil)).Elem()
+	bigIntType = reflect.TypeOf(big.Int{})
+)
+
+// FromGo converts a Go value to a Monkey object.
+//
+// Integers, strings, bools, nil, *big.Int, slices, arrays, maps with string
+// keys and structs are supported, as are pointers to them. Floats must hold
+// an integral value since Monkey has no floating point numbers. Struct
+// fields are named by their `monkey` tag, or else by the field name; a tag
+// of "-" skips the field. Objects are returned as they are. A value that
+// contains itself cannot be converted.
+func FromGo(v interface{}) (Object, error) {
+	return fromGo(reflect.ValueOf(v))
+}
+
+func fromGo(v reflect.Value) (Object, error) {
+	c := &converter{path: make(map[visit]bool)}
+	return c.fromGo(v)
+}
+
+// fromGoWrapped is like fromGo, but structs and pointers to structs are
+// wrapped in a GoValue allowing the given members instead of converted.
+func fromGoWrapped(v reflect.Value, allow map[string]bool) (Object, error) {
+	c := &converter{path: make(map[visit]bool), allow: allow}
+	return c.fromGo(v)
+}
+
+// visit identifies the pointer, map or slice a value refers to. Slices
+// starting at the same element but of different lengths are different
+// values.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+type converter struct {
+	path  map[visit]bool  // values being converted, from the root down
+	allow map[string]bool // members of wrapped structs, nil to convert structs
+}
+
+func (c *converter) fromGo(v reflect.Value) (Object, error) {
+	if !v.IsValid() {
+		return NULL, nil
+	}
+
+	if v.Type().Implements(objectType) {
+		if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+			return NULL, nil
+		}
+		return v.Interface().(Object), nil
+	}
+
+	if v.Type() == bigIntType {
+		b := v.Interface().(big.Int)
+		return NewInteger(new(big.Int).Set(&b)), nil
+	}
+
+	if c.allow != nil && isStruct(v) {
+		return &GoValue{Value: v, Allow: c.allow}, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			break
+		}
+		key := visit{ptr: v.Pointer(), typ: v.Type()}
+		if v.Kind() == reflect.Slice {
+			key.len = v.Len()
+		}
+		if c.path[key] {
+			return nil, errors.New("cannot convert cyclic value")
+		}
+		c.path[key] = true
+		defer delete(c.path, key)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return NULL, nil
+		}
+		return c.fromGo(v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			return TRUE, nil
+		}
+		return FALSE, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Integer{Value: v.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return NewInteger(new(big.Int).SetUint64(v.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != math.Trunc(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("cannot convert %v to INTEGER: not an integral value", f)
+		}
+		i, _ := big.NewFloat(f).Int(nil)
+		return NewInteger(i), nil
+	case reflect.String:
+		return &String{Value: v.String()}, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return NULL, nil
+		}
+		elems := make([]Object, v.Len())
+		for i := range elems {
+			e, err := c.fromGo(v.Index(i))
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %s", i, err)
+			}
+			elems[i] = e
+		}
+		return &Array{Elements: elems}, nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("cannot convert %s: map keys must be strings", v.Type())
+		}
+		if v.IsNil() {
+			return NULL, nil
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+		hash := &Hash{}
+		for _, k := range keys {
+			val, err := c.fromGo(v.MapIndex(k))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", k.String(), err)
+			}
+			key := &String{Value: k.String()}
+			hash.Set(key, val)
+		}
+		return hash, nil
+	case reflect.Struct:
+		hash := &Hash{}
+		for _, f := range structFields(v.Type()) {
+			val, err := c.fromGo(v.FieldByIndex(f.index))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", f.name, err)
+			}
+			key := &String{Value: f.name}
+			hash.Set(key, val)
+		}
+		return hash, nil
+	default:
+		return nil, fmt.Errorf("cannot convert %s to a Monkey object", v.Type())
+	}
+}
+
+// isStruct reports whether v is a struct or a non-nil pointer to one.
+func isStruct(v reflect.Value) bool {
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v.Kind() == reflect.Struct
+}
+
+// ToGo converts a Monkey object to a Go value: an int64, *big.Int, string,
+// bool, nil, []interface{} or map[string]interface{}. A GoValue is
+// unwrapped.
+func ToGo(obj Object) (interface{}, error) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return obj.Value, nil
+	case *BigInt:
+		return new(big.Int).Set(obj.Value), nil
+	case *String:
+		return obj.Value, nil
+	case *Boolean:
+		return obj.Value, nil
+	case *Null:
+		return nil, nil
+	case *Array:
+		result := make([]interface{}, len(obj.Elements))
+		for i, e := range obj.Elements {
+			v, err := ToGo(e)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %s", i, err)
+			}
+			result[i] = v
+		}
+		return result, nil
+	case *Hash:
+		result := make(map[string]interface{}, obj.Len())
+		for _, pair := range obj.Pairs() {
+			key, ok := pair.Key.(*String)
+			if !ok {
+				return nil, fmt.Errorf("cannot convert HASH with %s key: keys must be strings", pair.Key.Type())
+			}
+			v, err := ToGo(pair.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", key.Value, err)
+			}
+			result[key.Value] = v
+		}
+		return result, nil
+	case *GoValue:
+		if !obj.Value.IsValid() {
+			return nil, nil
+		}
+		return obj.Value.Interface(), nil
+	default:
+		return nil, fmt.Errorf("cannot convert %s to a Go value", obj.Type())
+	}
+}
+
+// Decode stores obj in the value pointed to by target, converting hashes
+// to structs and maps, arrays to slices and integers to any numeric type.
+// Struct fields are matched like FromGo names them, falling back to a
+// case-insensitive match. Hash keys without a field are ignored and null
+// leaves the target unchanged. A GoValue is stored as is if its type is
+// assignable to the target.
+func Decode(obj Object, target interface{}) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("decode target must be a non-nil pointer, got %T", target)
+	}
+
+	return decode(obj, v.Elem())
+}
+
+func decode(obj Object, v reflect.Value) error {
+	if obj.Type() == NULL_OBJ {
+		return nil
+	}
+
+	if v.Type().Implements(objectType) && reflect.TypeOf(obj).AssignableTo(v.Type()) {
+		v.Set(reflect.ValueOf(obj))
+		return nil
+	}
+
+	if g, ok := obj.(*GoValue); ok && g.Value.IsValid() && g.Value.Type().AssignableTo(v.Type()) {
+		v.Set(g.Value)
+		return nil
+	}
+
+	if v.Type() == bigIntType {
+		i, ok := toBigInt(obj)
+		if !ok {
+			return decodeError(obj, v)
+		}
+		v.Set(reflect.ValueOf(*new(big.Int).Set(i)))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decode(obj, v.Elem())
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return decodeError(obj, v)
+		}
+		g, err := ToGo(obj)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(g))
+	case reflect.Bool:
+		b, ok := obj.(*Boolean)
+		if !ok {
+			return decodeError(obj, v)
+		}
+		v.SetBool(b.Value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := toBigInt(obj)
+		if !ok || !i.IsInt64() || v.OverflowInt(i.Int64()) {
+			return decodeError(obj, v)
+		}
+		v.SetInt(i.Int64())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		i, ok := toBigInt(obj)
+		if !ok || !i.IsUint64() || v.OverflowUint(i.Uint64()) {
+			return decodeError(obj, v)
+		}
+		v.SetUint(i.Uint64())
+	case reflect.Float32, reflect.Float64:
+		i, ok := toBigInt(obj)
+		if !ok {
+			return decodeError(obj, v)
+		}
+		f, _ := new(big.Float).SetInt(i).Float64()
+		v.SetFloat(f)
+	case reflect.String:
+		s, ok := obj.(*String)
+		if !ok {
+			return decodeError(obj, v)
+		}
+		v.SetString(s.Value)
+	case reflect.Slice:
+		arr, ok := obj.(*Array)
+		if !ok {
+			return decodeError(obj, v)
+		}
+		slice := reflect.MakeSlice(v.Type(), len(arr.Elements), len(arr.Elements))
+		for i, e := range arr.Elements {
+			if err := decode(e, slice.Index(i)); err != nil {
+				return fmt.Errorf("[%d]: %s", i, err)
+			}
+		}
+		v.Set(slice)
+	case reflect.Map:
+		hash, ok := obj.(*Hash)
+		if !ok || v.Type().Key().Kind() != reflect.String {
+			return decodeError(obj, v)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for _, pair := range hash.Pairs() {
+			key, ok := pair.Key.(*String)
+			if !ok {
+				return fmt.Errorf("cannot decode %s key into %s", pair.Key.Type(), v.Type())
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := decode(pair.Value, elem); err != nil {
+				return fmt.Errorf("%s: %s", key.Value, err)
+			}
+			v.SetMapIndex(reflect.ValueOf(key.Value).Convert(v.Type().Key()), elem)
+		}
+	case reflect.Struct:
+		hash, ok := obj.(*Hash)
+		if !ok {
+			return decodeError(obj, v)
+		}
+		fields := structFields(v.Type())
+		for _, pair := range hash.Pairs() {
+			key, ok := pair.Key.(*String)
+			if !ok {
+				continue
+			}
+			f, ok := lookupField(fields, key.Value)
+			if !ok {
+				continue
+			}
+			if err := decode(pair.Value, v.FieldByIndex(f.index)); err != nil {
+				return fmt.Errorf("%s: %s", key.Value, err)
+			}
+		}
+	default:
+		return decodeError(obj, v)
+	}
+
+	return nil
+}
+
+func decodeError(obj Object, v reflect.Value) error {
+	return fmt.Errorf("cannot decode %s into %s", obj.Type(), v.Type())
+}
+
+func toBigInt(obj Object) (*big.Int, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return big.NewInt(obj.Value), true
+	case *BigInt:
+		return obj.Value, true
+	default:
+		return nil, false
+	}
+}
+
+// NewInteger returns v as an Integer if it fits in int64 and as a BigInt
+// otherwise, so that the same value always has the same type.
+func NewInteger(v *big.Int) Object {
+	if v.IsInt64() {
+		return &Integer{Value: v.Int64()}
+	}
+	return &BigInt{Value: v}
+}
+
+type structField struct {
+	name  string
+	index []int
+}
+
+// structFields lists the exported fields of t with their Monkey names.
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name := f.Name
+		if tag := f.Tag.Get("monkey"); tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+
+		fields = append(fields, structField{name: name, index: f.Index})
+	}
+
+	return fields
+}
+
+func lookupField(fields []structField, name string) (structField, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+	return structField{}, false
+}
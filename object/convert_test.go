@@ -0,0 +1,244 @@
+package object
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestFromGo(t *testing.T) {
+	type inner struct {
+		Port int `monkey:"port"`
+	}
+	type outer struct {
+		Name    string
+		Servers []inner `monkey:"servers"`
+		Secret  string  `monkey:"-"`
+		private int
+	}
+
+	tests := []struct {
+		name string
+		in   interface{}
+		exp  string
+	}{
+		{"nil", nil, "null"},
+		{"int", 5, "5"},
+		{"int8", int8(-5), "-5"},
+		{"uint64", uint64(1<<64 - 1), "18446744073709551615"},
+		{"float", 3.0, "3"},
+		{"big float", 1e20, "100000000000000000000"},
+		{"string", "a", "a"},
+		{"bool", true, "true"},
+		{"big", big.NewInt(7), "7"},
+		{"slice", []interface{}{1, "a", nil}, `[1, "a", null]`},
+		{"array", [2]bool{true, false}, "[true, false]"},
+		{"nil slice", []int(nil), "null"},
+		{"pointer", &[]int{1}, "[1]"},
+		{"nested", map[string][]int{"a": {1, 2}}, "[1, 2]"},
+		{"object", &Integer{Value: 1}, "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj, err := FromGo(tt.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if h, ok := obj.(*Hash); ok {
+				// only single pair hashes are used
+				for _, pair := range h.Pairs() {
+					obj = pair.Value
+				}
+			}
+
+			if obj.Inspect() != tt.exp {
+				t.Errorf("want %q, but %q", tt.exp, obj.Inspect())
+			}
+		})
+	}
+
+	t.Run("struct", func(t *testing.T) {
+		obj, err := FromGo(outer{Name: "api", Servers: []inner{{Port: 80}}, Secret: "x", private: 1})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		got, err := ToGo(obj)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		exp := map[string]interface{}{
+			"Name":    "api",
+			"servers": []interface{}{map[string]interface{}{"port": int64(80)}},
+		}
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("want %#v, but %#v", exp, got)
+		}
+	})
+
+	t.Run("null is a singleton", func(t *testing.T) {
+		if obj, _ := FromGo(nil); obj != NULL {
+			t.Errorf("want NULL, but %#v", obj)
+		}
+		if obj, _ := FromGo(false); obj != FALSE {
+			t.Errorf("want FALSE, but %#v", obj)
+		}
+	})
+}
+
+func TestFromGoShared(t *testing.T) {
+	// a value reached twice without a cycle is converted each time
+	shared := &struct{ X int }{X: 1}
+	obj, err := FromGo([]interface{}{shared, shared, map[string]interface{}{"s": shared}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	exp := `[{"X": 1}, {"X": 1}, {"s": {"X": 1}}]`
+	if obj.Inspect() != exp {
+		t.Errorf("want %q, but %q", exp, obj.Inspect())
+	}
+}
+
+func TestFromGoErrors(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+	}
+	n := &node{Name: "n"}
+	n.Next = n
+
+	m := map[string]interface{}{"a": 1}
+	m["self"] = m
+
+	s := []interface{}{1, nil}
+	s[1] = s
+
+	tests := []struct {
+		name string
+		in   interface{}
+		exp  string
+	}{
+		{"float", 1.5, "cannot convert 1.5 to INTEGER: not an integral value"},
+		{"map key", map[int]int{}, "cannot convert map[int]int: map keys must be strings"},
+		{"chan", make(chan int), "cannot convert chan int to a Monkey object"},
+		{"nested", map[string][]interface{}{"a": {1, func() {}}}, "a: [1]: cannot convert func() to a Monkey object"},
+		{"cyclic pointer", n, "Next: cannot convert cyclic value"},
+		{"cyclic map", m, "self: cannot convert cyclic value"},
+		{"cyclic slice", s, "[1]: cannot convert cyclic value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FromGo(tt.in)
+			if err == nil || err.Error() != tt.exp {
+				t.Errorf("want %q, but %v", tt.exp, err)
+			}
+		})
+	}
+}
+
+func TestToGo(t *testing.T) {
+	hash := &Hash{}
+	hash.Set(&String{Value: "k"}, &Array{Elements: []Object{TRUE, NULL}})
+
+	got, err := ToGo(hash)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	exp := map[string]interface{}{"k": []interface{}{true, nil}}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("want %#v, but %#v", exp, got)
+	}
+
+	hash.Set(&Integer{Value: 1}, NULL)
+	if _, err := ToGo(hash); err == nil || err.Error() != "cannot convert HASH with INTEGER key: keys must be strings" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if _, err := ToGo(&Function{}); err == nil || err.Error() != "cannot convert FUNCTION to a Go value" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	type limits struct {
+		Rate  float64
+		Burst uint8
+	}
+	type config struct {
+		Name    string            `monkey:"name"`
+		Hosts   []string          `monkey:"hosts"`
+		Limits  *limits           `monkey:"limits"`
+		Labels  map[string]string `monkey:"labels"`
+		Total   *big.Int          `monkey:"total"`
+		Extra   interface{}       `monkey:"extra"`
+		Enabled bool
+	}
+
+	in, err := FromGo(map[string]interface{}{
+		"name":    "api",
+		"hosts":   []string{"a", "b"},
+		"limits":  map[string]interface{}{"rate": 10, "burst": 3},
+		"labels":  map[string]string{"team": "core"},
+		"total":   new(big.Int).Lsh(big.NewInt(1), 70),
+		"extra":   []interface{}{1, "x"},
+		"enabled": true,
+		"unknown": 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got config
+	if err := Decode(in, &got); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	exp := config{
+		Name:    "api",
+		Hosts:   []string{"a", "b"},
+		Limits:  &limits{Rate: 10, Burst: 3},
+		Labels:  map[string]string{"team": "core"},
+		Total:   new(big.Int).Lsh(big.NewInt(1), 70),
+		Extra:   []interface{}{int64(1), "x"},
+		Enabled: true,
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("want %+v, but %+v", exp, got)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	type port struct {
+		Port uint8 `monkey:"port"`
+	}
+
+	tests := []struct {
+		name   string
+		in     interface{}
+		target interface{}
+		exp    string
+	}{
+		{"type", "a", new(int), "cannot decode STRING into int"},
+		{"overflow", 256, new(port), "cannot decode INTEGER into object.port"},
+		{"field overflow", map[string]int{"port": 256}, new(port), "port: cannot decode INTEGER into uint8"},
+		{"element", []interface{}{1, "a"}, new([]int), "[1]: cannot decode STRING into int"},
+		{"target", 1, port{}, "decode target must be a non-nil pointer, got object.port"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, err := FromGo(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = Decode(in, tt.target)
+			if err == nil || err.Error() != tt.exp {
+				t.Errorf("want %q, but %v", tt.exp, err)
+			}
+		})
+	}
+}
@@ -1,7 +1,13 @@
 package object
 
+import "sync"
+
 // Environment is HashMap
+//
+// It is safe for concurrent use, so closures and the root environment of
+// an interpreter may be shared between goroutines.
 type Environment struct {
+	mu    sync.RWMutex
 	store map[string]Object
 	outer *Environment
 }
@@ -18,7 +24,10 @@ func NewEnvironment() *Environment {
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
+	e.mu.RLock()
 	obj, ok := e.store[name]
+	e.mu.RUnlock()
+
 	if !ok && e.outer != nil {
 		obj, ok = e.outer.Get(name)
 	}
@@ -26,6 +35,8 @@ func (e *Environment) Get(name string) (Object, bool) {
 }
 
 func (e *Environment) Set(name string, obj Object) Object {
+	e.mu.Lock()
 	e.store[name] = obj
+	e.mu.Unlock()
 	return obj
 }
@@ -0,0 +1,164 @@
+package object
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var (
+	ctxType   = reflect.TypeOf((*CallContext)(nil)).Elem()
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// NewGoFunc returns a builtin that calls fn, a Go function or bound method.
+//
+// A first parameter of type CallContext is given the context of the call;
+// the other parameters are filled from the arguments of the script with
+// Decode. A non-nil error result is returned to the script as an Error, and
+// so is a panic of fn. The other result, if any, is converted with FromGo.
+func NewGoFunc(name, doc string, fn reflect.Value) *Builtin {
+	return newGoFunc(name, doc, fn, nil)
+}
+
+// newGoFunc is like NewGoFunc, but unless allow is nil a result holding a
+// struct or a pointer to one is wrapped in a GoValue allowing the given
+// members instead of converted.
+func newGoFunc(name, doc string, fn reflect.Value, allow map[string]bool) *Builtin {
+	t := fn.Type()
+
+	arity := t.NumIn() - firstArg(t)
+	if t.IsVariadic() {
+		arity = Variadic
+	}
+
+	return &Builtin{
+		Name:  name,
+		Arity: arity,
+		Doc:   doc,
+		Fn: func(ctx CallContext, args ...Object) Object {
+			return callGoFunc(ctx, name, fn, args, allow)
+		},
+	}
+}
+
+// firstArg is the index of the first parameter of t filled from script
+// arguments.
+func firstArg(t reflect.Type) int {
+	if t.NumIn() > 0 && t.In(0) == ctxType {
+		return 1
+	}
+	return 0
+}
+
+func callGoFunc(ctx CallContext, name string, fn reflect.Value, args []Object, allow map[string]bool) (result Object) {
+	t := fn.Type()
+	first := firstArg(t)
+	params := t.NumIn() - first
+
+	if t.IsVariadic() {
+		if len(args) < params-1 {
+			return &Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want at least %d", len(args), params-1)}
+		}
+	} else if len(args) != params {
+		return &Error{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=%d", len(args), params)}
+	}
+
+	in := make([]reflect.Value, first, first+len(args))
+	if first == 1 {
+		in[0] = reflect.ValueOf(&ctx).Elem()
+	}
+
+	for i, arg := range args {
+		var pt reflect.Type
+		if t.IsVariadic() && first+i >= t.NumIn()-1 {
+			pt = t.In(t.NumIn() - 1).Elem()
+		} else {
+			pt = t.In(first + i)
+		}
+
+		v, ok := toGoValue(arg, pt)
+		if !ok {
+			return &Error{Message: fmt.Sprintf("argument %d to `%s` must be %s, got=%s", i+1, name, monkeyTypeName(pt), arg.Type())}
+		}
+		in = append(in, v)
+	}
+
+	// a panic must not take down the host, e.g. a method on a nil receiver
+	defer func() {
+		if r := recover(); r != nil {
+			result = &Error{Message: fmt.Sprintf("panic in `%s`: %v", name, r)}
+		}
+	}()
+
+	out := fn.Call(in)
+
+	if len(out) > 0 && t.Out(len(out)-1) == errorType {
+		if err, _ := out[len(out)-1].Interface().(error); err != nil {
+			return &Error{Message: err.Error()}
+		}
+		out = out[:len(out)-1]
+	}
+
+	if len(out) == 0 {
+		return NULL
+	}
+
+	obj, err := fromGoWrapped(out[0], allow)
+	if err != nil {
+		return &Error{Message: fmt.Sprintf("result of `%s`: %s", name, err)}
+	}
+	return obj
+}
+
+func toGoValue(arg Object, t reflect.Type) (reflect.Value, bool) {
+	if t == objectType {
+		return reflect.ValueOf(&arg).Elem(), true
+	}
+	if reflect.TypeOf(arg).AssignableTo(t) {
+		return reflect.ValueOf(arg), true
+	}
+	// Decode leaves the target unchanged for null, which would pass a zero
+	// value for a missing argument
+	if arg == NULL {
+		return reflect.Value{}, false
+	}
+
+	v := reflect.New(t)
+	if err := Decode(arg, v.Interface()); err != nil {
+		return reflect.Value{}, false
+	}
+	return v.Elem(), true
+}
+
+// monkeyTypeName describes the Monkey values accepted for a Go type.
+func monkeyTypeName(t reflect.Type) string {
+	switch {
+	case t == objectType:
+		return "any value"
+	case t == bigIntType || t == reflect.PtrTo(bigIntType):
+		return INTEGER_OBJ
+	case t.Kind() == reflect.Interface:
+		// the zero value of an interface type has no Type method to call
+		if t.Name() == "" {
+			return "any value"
+		}
+		return t.Name()
+	case t.Implements(objectType):
+		return string(reflect.Zero(t).Interface().(Object).Type())
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return monkeyTypeName(t.Elem())
+	case reflect.String:
+		return STRING_OBJ
+	case reflect.Bool:
+		return BOOLEAN_OBJ
+	case reflect.Slice, reflect.Array:
+		return ARRAY_OBJ + " of " + monkeyTypeName(t.Elem())
+	case reflect.Map, reflect.Struct:
+		return HASH_OBJ
+	default:
+		return INTEGER_OBJ
+	}
+}
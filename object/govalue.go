@@ -0,0 +1,99 @@
+package object
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// GoValue wraps a Go value so that scripts can read its fields and call its
+// methods with index expressions, e.g. req["Method"] or req["Header"]("Accept").
+//
+// Only the members named in Allow are reachable. Field values and method
+// results are converted with FromGo and method arguments with Decode, except
+// that a struct or a pointer to one is wrapped in a GoValue of its own. Its
+// members are allowed as "Field.Member" or "Method.Member" in the allow-list
+// of the parent.
+type GoValue struct {
+	Value reflect.Value
+	Allow map[string]bool
+}
+
+// NewGoValue wraps v, allowing access to the given fields and methods.
+func NewGoValue(v interface{}, members ...string) *GoValue {
+	allow := make(map[string]bool, len(members))
+	for _, m := range members {
+		allow[m] = true
+	}
+
+	return &GoValue{Value: reflect.ValueOf(v), Allow: allow}
+}
+
+func (g *GoValue) Type() ObjectType {
+	return GO_VALUE_OBJ
+}
+
+func (g *GoValue) Inspect() string {
+	if !g.Value.IsValid() {
+		return "go value <nil>"
+	}
+	return fmt.Sprintf("go value %s", g.Value.Type())
+}
+
+// Member returns the field or method called name. A method is returned as a
+// builtin bound to the wrapped value.
+func (g *GoValue) Member(name string) (Object, error) {
+	if !g.Allow[name] || !g.Value.IsValid() {
+		return nil, fmt.Errorf("%s has no accessible member %s", g.Inspect(), name)
+	}
+
+	if m := g.method(name); m.IsValid() {
+		return newGoFunc(g.Value.Type().String()+"."+name, "", m, g.memberAllow(name)), nil
+	}
+
+	v := g.Value
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, fmt.Errorf("%s is nil", g.Inspect())
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.Struct {
+		if f, ok := v.Type().FieldByName(name); ok && f.PkgPath == "" {
+			obj, err := fromGoWrapped(v.FieldByIndex(f.Index), g.memberAllow(name))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", name, err)
+			}
+			return obj, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s has no accessible member %s", g.Inspect(), name)
+}
+
+// memberAllow returns the allow-list of the value of a member: the allowed
+// names that start with the name of the member and a dot, without that
+// prefix.
+func (g *GoValue) memberAllow(name string) map[string]bool {
+	allow := make(map[string]bool)
+	for m, ok := range g.Allow {
+		if ok && strings.HasPrefix(m, name+".") {
+			allow[strings.TrimPrefix(m, name+".")] = true
+		}
+	}
+	return allow
+}
+
+func (g *GoValue) method(name string) reflect.Value {
+	if m := g.Value.MethodByName(name); m.IsValid() {
+		return m
+	}
+
+	// methods with a pointer receiver of an addressable value
+	if g.Value.Kind() != reflect.Ptr && g.Value.CanAddr() {
+		return g.Value.Addr().MethodByName(name)
+	}
+
+	return reflect.Value{}
+}
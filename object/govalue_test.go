@@ -0,0 +1,218 @@
+package object
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testRequest struct {
+	Method  string
+	Path    string
+	Secret  string
+	headers map[string]string
+}
+
+func (r *testRequest) Header(name string) string {
+	return r.headers[strings.ToLower(name)]
+}
+
+func (r *testRequest) SetHeader(name, value string) {
+	r.headers[strings.ToLower(name)] = value
+}
+
+func (r *testRequest) Join(sep string, parts ...string) string {
+	return strings.Join(parts, sep)
+}
+
+func (r *testRequest) Fail() (int, error) {
+	return 0, errors.New("failed")
+}
+
+func (r *testRequest) Delete() {}
+
+func (r *testRequest) Clone() *testRequest {
+	c := *r
+	return &c
+}
+
+func TestGoValueMember(t *testing.T) {
+	req := &testRequest{Method: "GET", Path: "/", Secret: "s", headers: map[string]string{"accept": "text/plain"}}
+	g := NewGoValue(req, "Method", "Path", "Header", "SetHeader", "Join", "Fail")
+
+	call := func(name string, args ...Object) Object {
+		m, err := g.Member(name)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		b, ok := m.(*Builtin)
+		if !ok {
+			t.Fatalf("%s is not *Builtin. got=%T", name, m)
+		}
+		return b.Fn(nil, args...)
+	}
+
+	if m, err := g.Member("Method"); err != nil || m.Inspect() != "GET" {
+		t.Errorf("want %q, but %v (%v)", "GET", m, err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		args   []Object
+		exp    string
+	}{
+		{"call", "Header", []Object{&String{Value: "Accept"}}, "text/plain"},
+		{"no result", "SetHeader", []Object{&String{Value: "X"}, &String{Value: "1"}}, "null"},
+		{"variadic", "Join", []Object{&String{Value: "-"}, &String{Value: "a"}, &String{Value: "b"}}, "a-b"},
+		{"error", "Fail", nil, "ERROR: failed"},
+		{"argument count", "Header", nil, "ERROR: wrong number of arguments. got=0, want=1"},
+		{"argument type", "Header", []Object{&Integer{Value: 1}}, "ERROR: argument 1 to `*object.testRequest.Header` must be STRING, got=INTEGER"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := call(tt.method, tt.args...)
+			if got.Inspect() != tt.exp {
+				t.Errorf("want %q, but %q", tt.exp, got.Inspect())
+			}
+		})
+	}
+
+	if req.headers["x"] != "1" {
+		t.Errorf("method was not called on the wrapped value")
+	}
+}
+
+func TestGoValueAllowList(t *testing.T) {
+	g := NewGoValue(&testRequest{}, "Method", "headers", "Missing")
+
+	for _, name := range []string{"Secret", "Delete", "headers", "Missing"} {
+		if _, err := g.Member(name); err == nil {
+			t.Errorf("%s should not be accessible", name)
+		}
+	}
+
+	_, err := g.Member("Secret")
+	if err.Error() != "go value *object.testRequest has no accessible member Secret" {
+		t.Errorf("wrong error %q", err)
+	}
+}
+
+func TestGoValueMethodResult(t *testing.T) {
+	req := &testRequest{Method: "GET", Secret: "s"}
+	g := NewGoValue(req, "Clone", "Clone.Method")
+
+	m, err := g.Member("Clone")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	result := m.(*Builtin).Fn(nil)
+
+	// the result is wrapped like a field, not converted to a hash
+	clone, ok := result.(*GoValue)
+	if !ok {
+		t.Fatalf("result is not *GoValue. got=%T (%s)", result, result.Inspect())
+	}
+	if m, err := clone.Member("Method"); err != nil || m.Inspect() != "GET" {
+		t.Errorf("want %q, but %v (%v)", "GET", m, err)
+	}
+	if _, err := clone.Member("Secret"); err == nil {
+		t.Errorf("Secret should not be accessible")
+	}
+}
+
+func TestGoValueConvert(t *testing.T) {
+	req := &testRequest{Method: "POST"}
+	g := NewGoValue(req)
+
+	v, err := ToGo(g)
+	if err != nil || v != req {
+		t.Errorf("want %p, but %v (%v)", req, v, err)
+	}
+
+	var got *testRequest
+	if err := Decode(g, &got); err != nil || got != req {
+		t.Errorf("want %p, but %p (%v)", req, got, err)
+	}
+}
+
+type testServer struct {
+	Host   string
+	Port   int
+	Config testConfig
+	Parent *testServer
+	Peers  []testServer
+}
+
+type testConfig struct {
+	Debug bool
+	Token string
+}
+
+func TestGoValueNestedStruct(t *testing.T) {
+	s := &testServer{Host: "a", Port: 80, Config: testConfig{Debug: true, Token: "secret"}}
+	s.Parent = s
+	s.Peers = []testServer{{Host: "b"}}
+	g := NewGoValue(s, "Config", "Config.Debug", "Parent", "Parent.Host", "Peers")
+
+	member := func(g Object, names ...string) (Object, error) {
+		var err error
+		for _, name := range names {
+			gv, ok := g.(*GoValue)
+			if !ok {
+				t.Fatalf("%s is not *GoValue. got=%T", name, g)
+			}
+			if g, err = gv.Member(name); err != nil {
+				return nil, err
+			}
+		}
+		return g, nil
+	}
+
+	tests := []struct {
+		name string
+		path []string
+		exp  string
+		err  string
+	}{
+		{name: "struct field", path: []string{"Config"}, exp: "go value object.testConfig"},
+		{name: "allowed nested field", path: []string{"Config", "Debug"}, exp: "true"},
+		{name: "nested field not allowed", path: []string{"Config", "Token"}, err: "go value object.testConfig has no accessible member Token"},
+		{name: "cyclic pointer", path: []string{"Parent", "Host"}, exp: "a"},
+		{name: "cyclic pointer twice", path: []string{"Parent", "Parent"}, err: "go value *object.testServer has no accessible member Parent"},
+		{name: "slice of structs", path: []string{"Peers"}, exp: "[go value object.testServer]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := member(g, tt.path...)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("want %q, but %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got.Inspect() != tt.exp {
+				t.Errorf("want %q, but %q", tt.exp, got.Inspect())
+			}
+		})
+	}
+}
+
+func TestGoValueNilReceiver(t *testing.T) {
+	var req *testRequest
+	g := NewGoValue(req, "Header")
+
+	m, err := g.Member("Header")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got := m.(*Builtin).Fn(nil, &String{Value: "Accept"})
+	exp := "ERROR: panic in `*object.testRequest.Header`: runtime error: invalid memory address or nil pointer dereference"
+	if got.Inspect() != exp {
+		t.Errorf("want %q, but %q", exp, got.Inspect())
+	}
+}
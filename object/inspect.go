@@ -0,0 +1,68 @@
+package object
+
+import "bytes"
+
+// Objects have two printed forms. Inspect gives the display form, which puts
+// prints: a string is written as it is. Repr gives the form that shows the
+// type of a value, like the REPL does: a string is written in quotes. The
+// strings inside arrays and hashes are quoted in both forms.
+
+// Repr returns the repr form of obj.
+func Repr(obj Object) string {
+	var out bytes.Buffer
+	writeRepr(&out, obj, nil)
+	return out.String()
+}
+
+// writeRepr writes the repr form of obj to out. path holds the arrays and
+// hashes obj is nested in; a container that contains itself is written as
+// [...] or {...} where it repeats.
+func writeRepr(out *bytes.Buffer, obj Object, path []Object) {
+	switch obj := obj.(type) {
+	case *String:
+		out.WriteString(`"` + obj.Value + `"`)
+	case *Array:
+		if onPath(obj, path) {
+			out.WriteString("[...]")
+			return
+		}
+		path = append(path, obj)
+
+		out.WriteString("[")
+		for i, e := range obj.Elements {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+			writeRepr(out, e, path)
+		}
+		out.WriteString("]")
+	case *Hash:
+		if onPath(obj, path) {
+			out.WriteString("{...}")
+			return
+		}
+		path = append(path, obj)
+
+		out.WriteString("{")
+		for i, pair := range obj.Pairs() {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+			writeRepr(out, pair.Key, path)
+			out.WriteString(": ")
+			writeRepr(out, pair.Value, path)
+		}
+		out.WriteString("}")
+	default:
+		out.WriteString(obj.Inspect())
+	}
+}
+
+func onPath(obj Object, path []Object) bool {
+	for _, o := range path {
+		if o == obj {
+			return true
+		}
+	}
+	return false
+}
@@ -2,21 +2,36 @@ package object
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"hash/fnv"
+	"io"
+	"math/big"
+	"reflect"
 	"strings"
 
 	"github.com/smith-30/go-monkey/ast"
+	"github.com/smith-30/go-monkey/token"
 )
 
 type ObjectType string
 
+// null and booleans are singletons, compare them by identity
+var (
+	NULL = &Null{}
+
+	TRUE  = &Boolean{Value: true}
+	FALSE = &Boolean{Value: false}
+)
+
 type Object interface {
 	Type() ObjectType
 	Inspect() string
 }
 
+// Hashable objects can be used as keys of a Hash.
 type Hashable interface {
+	Object
 	HashKey() HashKey
 }
 
@@ -32,6 +47,20 @@ func (i *Integer) Type() ObjectType {
 	return INTEGER_OBJ
 }
 
+// BigInt is an integer that does not fit in int64.
+// The evaluator only produces it when a result overflows Integer.
+type BigInt struct {
+	Value *big.Int
+}
+
+func (bi *BigInt) Inspect() string {
+	return bi.Value.String()
+}
+
+func (bi *BigInt) Type() ObjectType {
+	return BIGINT_OBJ
+}
+
 type String struct {
 	Value string
 }
@@ -78,12 +107,49 @@ func (rv *ReturnValue) Type() ObjectType {
 	return RETURN_VALUE_OBJ
 }
 
+// Error aborts evaluation until it is caught by a try expression.
+// A caught error is bound as a soft error, which is a plain value.
 type Error struct {
 	Message string
+	Pos     token.Position // position of the node that failed
+	Stack   []Frame        // function calls the error passed through, innermost first
+	Value   Object         // value given to throw, nil for runtime errors
+	Cause   *Error         // wrapped error, nil if there is none
+	Soft    bool           // soft errors do not abort evaluation
+}
+
+// Frame is a single function call in an error's call stack.
+type Frame struct {
+	Function string         // name of the let binding, empty for anonymous functions
+	Pos      token.Position // position of the call expression
+}
+
+func (f Frame) String() string {
+	name := f.Function
+	if name == "" {
+		name = "<anonymous>"
+	}
+	return fmt.Sprintf("in %s, called at %s", name, f.Pos)
 }
 
 func (e *Error) Inspect() string {
-	return "ERROR: " + e.Message
+	var out bytes.Buffer
+
+	out.WriteString("ERROR: " + e.Message)
+	if e.Pos.IsValid() {
+		out.WriteString(" at " + e.Pos.String())
+	}
+
+	for _, f := range e.Stack {
+		out.WriteString("\n\t" + f.String())
+	}
+
+	if e.Cause != nil {
+		out.WriteString("\ncaused by: ")
+		out.WriteString(strings.TrimPrefix(e.Cause.Inspect(), "ERROR: "))
+	}
+
+	return out.String()
 }
 
 func (e *Error) Type() ObjectType {
@@ -91,6 +157,8 @@ func (e *Error) Type() ObjectType {
 }
 
 type Function struct {
+	Name       string
+	Generator  bool // calls return an Iterator over the yielded values
 	Parameters []*ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
@@ -111,21 +179,113 @@ func (f *Function) Inspect() string {
 	out.WriteString("fn")
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
-	out.WriteString(") {\n")
+	out.WriteString(") ")
 	out.WriteString(f.Body.String())
-	out.WriteString("\n}")
 
 	return out.String()
 }
 
-type BuiltinFunction func(args ...Object) Object
+// Quote holds an unevaluated AST node, see quote.
+type Quote struct {
+	Node ast.Node
+}
+
+func (q *Quote) Type() ObjectType {
+	return QUOTE_OBJ
+}
+
+func (q *Quote) Inspect() string {
+	return "QUOTE(" + q.Node.String() + ")"
+}
+
+// Macro is a function from AST nodes to AST nodes, called before
+// evaluation. Its arguments are quoted and it must return a Quote.
+type Macro struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        *Environment
+}
+
+func (m *Macro) Type() ObjectType {
+	return MACRO_OBJ
+}
+
+func (m *Macro) Inspect() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range m.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString("macro")
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") ")
+	out.WriteString(m.Body.String())
+
+	return out.String()
+}
+
+// CallContext gives a builtin access to the evaluation that called it.
+type CallContext interface {
+	// Apply calls fn, a Monkey function or builtin, with args and returns
+	// its result. An error that aborts evaluation should be returned by the
+	// builtin as is.
+	Apply(fn Object, args ...Object) Object
+
+	// Context is done when the evaluation is stopped.
+	Context() context.Context
+
+	Stdout() io.Writer
+	Stderr() io.Writer
+
+	// Check returns an error if the policy of the evaluation does not allow
+	// access to resource, and nil otherwise. Builtins that touch files, the
+	// network or the output should check before doing so and return the
+	// error as is.
+	Check(c Capability, resource string) *Error
+}
+
+// Capability is a kind of access a builtin asks for with CallContext.Check.
+type Capability int
+
+const (
+	FileAccess    Capability = iota // resource is a file path
+	NetworkAccess                   // resource is a host or host:port
+	OutputAccess                    // resource is ignored
+)
+
+func (c Capability) String() string {
+	switch c {
+	case FileAccess:
+		return "file access"
+	case NetworkAccess:
+		return "network access"
+	case OutputAccess:
+		return "output"
+	default:
+		return fmt.Sprintf("Capability(%d)", int(c))
+	}
+}
+
+type BuiltinFunction func(ctx CallContext, args ...Object) Object
+
+// Variadic is the Arity of a builtin that accepts any number of arguments.
+const Variadic = -1
 
 type Builtin struct {
-	Fn BuiltinFunction
+	Fn    BuiltinFunction
+	Name  string
+	Arity int // number of arguments Fn expects, or Variadic
+	Doc   string
 }
 
 func (b *Builtin) Inspect() string {
-	return "builtin function"
+	if b.Name == "" {
+		return "builtin function"
+	}
+	return "builtin function " + b.Name
 }
 
 func (b *Builtin) Type() ObjectType {
@@ -141,20 +301,27 @@ func (a *Array) Type() ObjectType {
 }
 
 func (a *Array) Inspect() string {
-	var out bytes.Buffer
+	return Repr(a)
+}
 
-	params := []string{}
-	for _, e := range a.Elements {
-		params = append(params, e.Inspect())
-	}
+// Iterator produces a sequence of values lazily. Next returns the next
+// value and true, or false once the sequence is exhausted. An Error
+// returned by Next ends the sequence. ctx is the context of the caller,
+// functions that compute the values are applied with it.
+type Iterator struct {
+	Next func(ctx CallContext) (Object, bool)
+}
 
-	out.WriteString("[")
-	out.WriteString(strings.Join(params, ", "))
-	out.WriteString("]")
+func (it *Iterator) Type() ObjectType {
+	return ITERATOR_OBJ
+}
 
-	return out.String()
+func (it *Iterator) Inspect() string {
+	return "iterator"
 }
 
+// HashKey is a digest of a hash key. Different keys may have the same
+// HashKey, Hash compares the keys themselves to tell them apart.
 type HashKey struct {
 	Type  ObjectType
 	Value uint64
@@ -176,6 +343,13 @@ func (i *Integer) HashKey() HashKey {
 	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
 }
 
+func (bi *BigInt) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(bi.Value.String()))
+
+	return HashKey{Type: bi.Type(), Value: h.Sum64()}
+}
+
 func (s *String) HashKey() HashKey {
 	// if data expects under 32bit, should use fnv1a
 	h := fnv.New64a()
@@ -189,25 +363,86 @@ type HashPair struct {
 	Value Object
 }
 
+// Hash maps keys to values and remembers the order the keys were first
+// set in. The zero Hash is empty and ready to use.
 type Hash struct {
-	Pairs map[HashKey]HashPair
+	pairs   []HashPair        // in insertion order
+	buckets map[HashKey][]int // indexes in pairs of the keys with the same HashKey
 }
 
-func (h *Hash) Type() ObjectType {
-	return HASH_OBJ
+// Set sets the value of key. A new key is added after the existing ones,
+// an existing key keeps its place.
+func (h *Hash) Set(key Hashable, value Object) {
+	hk, i := h.find(key)
+	if i >= 0 {
+		h.pairs[i].Value = value
+		return
+	}
+
+	if h.buckets == nil {
+		h.buckets = make(map[HashKey][]int)
+	}
+	h.buckets[hk] = append(h.buckets[hk], len(h.pairs))
+	h.pairs = append(h.pairs, HashPair{Key: key, Value: value})
 }
 
-func (h *Hash) Inspect() string {
-	var out bytes.Buffer
+// Get returns the value of key and whether the hash has it.
+func (h *Hash) Get(key Hashable) (Object, bool) {
+	_, i := h.find(key)
+	if i < 0 {
+		return nil, false
+	}
+	return h.pairs[i].Value, true
+}
 
-	pairs := []string{}
-	for _, pair := range h.Pairs {
-		pairs = append(pairs, fmt.Sprintf("%s: %s", pair.Key.Inspect(), pair.Value.Inspect()))
+// find returns the HashKey of key and the index of its pair, or -1 if the
+// hash does not have it.
+func (h *Hash) find(key Hashable) (HashKey, int) {
+	hk := key.HashKey()
+	for _, i := range h.buckets[hk] {
+		if keysEqual(h.pairs[i].Key, key) {
+			return hk, i
+		}
+	}
+	return hk, -1
+}
+
+// keysEqual reports whether two keys with the same HashKey are equal.
+func keysEqual(a, b Object) bool {
+	// a host type may report the ObjectType of a built-in one
+	switch a := a.(type) {
+	case *String:
+		b, ok := b.(*String)
+		return ok && a.Value == b.Value
+	case *Integer:
+		b, ok := b.(*Integer)
+		return ok && a.Value == b.Value
+	case *BigInt:
+		b, ok := b.(*BigInt)
+		return ok && a.Value.Cmp(b.Value) == 0
+	case *Boolean:
+		b, ok := b.(*Boolean)
+		return ok && a.Value == b.Value
+	default:
+		return reflect.DeepEqual(a, b)
 	}
+}
 
-	out.WriteString("{")
-	out.WriteString(strings.Join(pairs, ", "))
-	out.WriteString("}")
+// Len returns the number of pairs.
+func (h *Hash) Len() int {
+	return len(h.pairs)
+}
 
+// Pairs returns the pairs in insertion order. The slice must not be
+// modified.
+func (h *Hash) Pairs() []HashPair {
+	return h.pairs
+}
+
+func (h *Hash) Type() ObjectType {
 	return HASH_OBJ
 }
+
+func (h *Hash) Inspect() string {
+	return Repr(h)
+}
@@ -22,3 +22,162 @@ func TestStringHashKey(t *testing.T) {
 	}
 
 }
+
+func TestEnvironmentConcurrent(t *testing.T) {
+	root := NewEnvironment()
+	done := make(chan struct{})
+
+	for n := 0; n < 4; n++ {
+		go func(n int) {
+			defer func() { done <- struct{}{} }()
+
+			env := NewEnclosedEnvironment(root)
+			for i := 0; i < 100; i++ {
+				root.Set("shared", &Integer{Value: int64(i)})
+				env.Set("local", &Integer{Value: int64(n)})
+				if _, ok := env.Get("shared"); !ok {
+					t.Error("shared is not visible from the enclosed environment")
+				}
+			}
+		}(n)
+	}
+
+	for n := 0; n < 4; n++ {
+		<-done
+	}
+}
+
+func TestInspect(t *testing.T) {
+	hash := &Hash{}
+	hash.Set(&String{Value: "b"}, &Integer{Value: 1})
+	hash.Set(&Integer{Value: 2}, &Array{Elements: []Object{&String{Value: "x"}, TRUE}})
+	hash.Set(TRUE, NULL)
+
+	cyclic := &Array{Elements: []Object{&Integer{Value: 1}}}
+	cyclic.Elements = append(cyclic.Elements, cyclic)
+
+	cyclicHash := &Hash{}
+	cyclicHash.Set(&String{Value: "self"}, cyclicHash)
+	cyclicHash.Set(&String{Value: "list"}, &Array{Elements: []Object{cyclicHash}})
+
+	shared := &Array{Elements: []Object{&String{Value: "s"}}}
+
+	tests := []struct {
+		name    string
+		obj     Object
+		inspect string
+		repr    string
+	}{
+		{"string", &String{Value: "a b"}, `a b`, `"a b"`},
+		{"integer", &Integer{Value: -3}, `-3`, `-3`},
+		{"array", &Array{Elements: []Object{&String{Value: "a"}, &String{Value: "b"}}}, `["a", "b"]`, `["a", "b"]`},
+		{"empty hash", &Hash{}, `{}`, `{}`},
+		{"hash", hash, `{"b": 1, 2: ["x", true], true: null}`, `{"b": 1, 2: ["x", true], true: null}`},
+		{"cyclic array", cyclic, `[1, [...]]`, `[1, [...]]`},
+		{"cyclic hash", cyclicHash, `{"self": {...}, "list": [{...}]}`, `{"self": {...}, "list": [{...}]}`},
+		{"shared, not cyclic", &Array{Elements: []Object{shared, shared}}, `[["s"], ["s"]]`, `[["s"], ["s"]]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.obj.Inspect(); got != tt.inspect {
+				t.Errorf("want %q, but %q", tt.inspect, got)
+			}
+			if got := Repr(tt.obj); got != tt.repr {
+				t.Errorf("want %q, but %q", tt.repr, got)
+			}
+		})
+	}
+}
+
+// collidingKey is a key whose HashKey is the same for every value.
+type collidingKey struct {
+	name string
+}
+
+func (k *collidingKey) Type() ObjectType { return "COLLIDING" }
+func (k *collidingKey) Inspect() string  { return k.name }
+func (k *collidingKey) HashKey() HashKey { return HashKey{Type: k.Type(), Value: 42} }
+
+func TestHashCollisions(t *testing.T) {
+	hash := &Hash{}
+	hash.Set(&collidingKey{"a"}, &Integer{Value: 1})
+	hash.Set(&collidingKey{"b"}, &Integer{Value: 2})
+	hash.Set(&String{Value: "a"}, &Integer{Value: 3})
+	hash.Set(&collidingKey{"a"}, &Integer{Value: 4})
+
+	tests := []struct {
+		name string
+		key  Hashable
+		exp  string
+	}{
+		{"first of bucket", &collidingKey{"a"}, "4"},
+		{"second of bucket", &collidingKey{"b"}, "2"},
+		{"other type", &String{Value: "a"}, "3"},
+		{"missing from bucket", &collidingKey{"c"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if v, ok := hash.Get(tt.key); ok {
+				got = v.Inspect()
+			}
+			if got != tt.exp {
+				t.Errorf("want %q, but %q", tt.exp, got)
+			}
+		})
+	}
+
+	if hash.Len() != 3 {
+		t.Errorf("want 3 pairs, but %d", hash.Len())
+	}
+	if got := hash.Inspect(); got != `{a: 4, b: 2, "a": 3}` {
+		t.Errorf("want %q, but %q", `{a: 4, b: 2, "a": 3}`, got)
+	}
+}
+
+// hostString is a host type that reports the ObjectType of a String and
+// has the same HashKey as a String with its value.
+type hostString struct {
+	value string
+}
+
+func (s *hostString) Type() ObjectType { return STRING_OBJ }
+func (s *hostString) Inspect() string  { return s.value }
+func (s *hostString) HashKey() HashKey { return (&String{Value: s.value}).HashKey() }
+
+func TestHashStringCollisions(t *testing.T) {
+	x, y := &String{Value: "x"}, &String{Value: "y"}
+
+	// make the two strings share a bucket, with x first
+	hash := &Hash{}
+	hash.Set(x, &Integer{Value: 1})
+	hash.buckets[y.HashKey()] = hash.buckets[x.HashKey()]
+	hash.Set(y, &Integer{Value: 2})
+	hash.buckets[x.HashKey()] = hash.buckets[y.HashKey()]
+	hash.Set(&hostString{"x"}, &Integer{Value: 3})
+
+	tests := []struct {
+		name string
+		key  Hashable
+		exp  string
+	}{
+		{"first of bucket", &String{Value: "x"}, "1"},
+		{"second of bucket", &String{Value: "y"}, "2"},
+		{"host type", &hostString{"x"}, "3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if v, ok := hash.Get(tt.key); ok {
+				got = v.Inspect()
+			}
+			if got != tt.exp {
+				t.Errorf("want %q, but %q", tt.exp, got)
+			}
+		})
+	}
+
+	if hash.Len() != 3 {
+		t.Errorf("want 3 pairs, but %d", hash.Len())
+	}
+}
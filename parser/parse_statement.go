@@ -13,6 +13,10 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.THROW:
+		return p.parseThrowStatement()
+	case token.YIELD:
+		return p.parseYieldStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -33,6 +37,47 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return stmt
 }
 
+func (p *Parser) parseThrowStatement() *ast.ThrowStatement {
+	stmt := &ast.ThrowStatement{Token: p.currentToken}
+
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseYieldStatement parses a yield, which makes the enclosing function
+// literal a generator.
+func (p *Parser) parseYieldStatement() *ast.YieldStatement {
+	stmt := &ast.YieldStatement{Token: p.currentToken}
+
+	if len(p.functions) == 0 {
+		p.errors = append(p.errors, "yield outside of function")
+	} else {
+		p.functions[len(p.functions)-1].Generator = true
+	}
+
+	if p.peekTokenIs(token.ASTERISK) {
+		p.nextToken()
+		stmt.Delegate = true
+	}
+
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseLetStatement() *ast.LetStatement {
 	stmt := &ast.LetStatement{Token: p.currentToken}
 
@@ -50,6 +95,11 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 
 	stmt.Value = p.parseExpression(LOWEST)
 
+	// remember the binding name so that runtime errors can name the function
+	if fl, ok := stmt.Value.(*ast.FunctionLiteral); ok {
+		fl.Name = stmt.Name.Value
+	}
+
 	// Todo: We skipped reading until it reaches the semicolon.
 	if !p.currentTokenIs(token.SEMICOLON) {
 		p.nextToken()
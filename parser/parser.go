@@ -20,6 +20,11 @@ type (
 
 		prefixParseFns map[token.TokenType]prefixParseFn
 		infixParseFns  map[token.TokenType]infixParseFn
+
+		// function literals being parsed, innermost last
+		functions []*ast.FunctionLiteral
+
+		traceLevel int
 	}
 )
 
@@ -62,7 +67,9 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.FALSE, p.parseBoolean)
 	p.registerPrefix(token.LPAREN, p.parseGroupExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
+	p.registerPrefix(token.TRY, p.parseTryExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
+	p.registerPrefix(token.MACRO, p.parseMacroLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
@@ -136,6 +143,10 @@ func (p *Parser) ParseProgram() *ast.Program {
 		p.nextToken()
 	}
 
+	for _, c := range p.l.Comments() {
+		program.Comments = append(program.Comments, &ast.Comment{Token: c.Token, Trailing: c.Trailing})
+	}
+
 	return program
 }
 
@@ -166,7 +177,6 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 
 func (p *Parser) parseHashLiteral() ast.Expression {
 	hash := &ast.HashLiteral{Token: p.currentToken}
-	hash.Pairs = make(map[ast.Expression]ast.Expression)
 
 	for !p.peekTokenIs(token.RBRACE) {
 		p.nextToken()
@@ -178,7 +188,7 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 
 		p.nextToken()
 		val := p.parseExpression(LOWEST)
-		hash.Pairs[key] = val
+		hash.Pairs = append(hash.Pairs, ast.HashPair{Key: key, Value: val})
 
 		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
 			return nil
@@ -189,6 +199,7 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 		return nil
 	}
 
+	hash.Rbrace = p.currentToken.Pos
 	return hash
 }
 
@@ -214,6 +225,7 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	}
 
 	expression.Arguments = p.parseExpressionList(token.RPAREN)
+	expression.Rparen = p.currentToken.Pos
 	return expression
 }
 
@@ -239,6 +251,7 @@ func (p *Parser) parseArrayLiteral() ast.Expression {
 	}
 
 	arr.Elements = p.parseExpressionList(token.RBRACKET)
+	arr.Rbracket = p.currentToken.Pos
 
 	return arr
 }
@@ -293,7 +306,7 @@ func (p *Parser) parseCallArguments() []ast.Expression {
 }
 
 // tips
-// set first row: defer untrace(trace("parseInfixExpression"))  for debug
+// set first row: defer p.untrace(p.trace("parseInfixExpression"))  for debug
 func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	expression := &ast.InfixExpression{
 		Token:    p.currentToken,
@@ -357,6 +370,58 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return expression
 }
 
+func (p *Parser) parseTryExpression() ast.Expression {
+	expression := &ast.TryExpression{Token: p.currentToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Block = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.CATCH) {
+		p.nextToken()
+
+		if !p.expectPeek(token.LPAREN) {
+			return nil
+		}
+
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		expression.Param = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+		if !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+
+		expression.Catch = p.parseBlockStatement()
+	}
+
+	if p.peekTokenIs(token.FINALLY) {
+		p.nextToken()
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+
+		expression.Finally = p.parseBlockStatement()
+	}
+
+	if expression.Catch == nil && expression.Finally == nil {
+		msg := fmt.Sprintf("expected catch or finally after try block, got %s instead", p.peekToken.Type)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	return expression
+}
+
 func (p *Parser) parseFunctionLiteral() ast.Expression {
 	lit := &ast.FunctionLiteral{Token: p.currentToken}
 
@@ -370,6 +435,26 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 		return nil
 	}
 
+	p.functions = append(p.functions, lit)
+	lit.Body = p.parseBlockStatement()
+	p.functions = p.functions[:len(p.functions)-1]
+
+	return lit
+}
+
+func (p *Parser) parseMacroLiteral() ast.Expression {
+	lit := &ast.MacroLiteral{Token: p.currentToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	lit.Parameters = p.parseFunctionParameters()
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
 	lit.Body = p.parseBlockStatement()
 
 	return lit
@@ -416,5 +501,6 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 		p.nextToken()
 	}
 
+	block.Rbrace = p.currentToken.Pos
 	return block
 }
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/smith-30/go-monkey/ast"
@@ -666,7 +667,7 @@ func TestIntegerLiteralExpression(t *testing.T) {
 					continue
 				}
 				if literal.Value != tt.exp.value {
-					t.Errorf("literal.Value is not %q, got %q", tt.exp.value, literal.Value)
+					t.Errorf("literal.Value is not %d, got %d", tt.exp.value, literal.Value)
 				}
 
 				if literal.TokenLiteral() != tt.exp.literal {
@@ -1208,19 +1209,67 @@ func TestParsingHashLiteralStringKeys(t *testing.T) {
 				t.Fatalf("len(hash.Pairs) not 3. got=%d", len(hash.Pairs))
 			}
 
-			for k, v := range hash.Pairs {
+			for _, pair := range hash.Pairs {
+				k, v := pair.Key, pair.Value
 				literal, ok := k.(*ast.StringLiteral)
 				if !ok {
 					t.Errorf("k is not ast.StringLiteral. got=%T", k)
 				}
 
-				expVal := tt.exp[literal.String()]
+				expVal := tt.exp[literal.Value]
 				testIntegerLiteral(t, v, expVal)
 			}
 		})
 	}
 }
 
+func TestParsingHashLiteralOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   []string
+	}{
+		{
+			name:  "keys in source order",
+			input: `{"c": 1, "a": 2, "b": 3}`,
+			exp:   []string{`"c"`, `"a"`, `"b"`},
+		},
+		{
+			name:  "keys of mixed types",
+			input: `{true: 1, 2: 2, "x" + "y": 3, false: 4}`,
+			exp:   []string{"true", "2", `("x" + "y")`, "false"},
+		},
+		{
+			name:  "duplicate keys",
+			input: `{"a": 1, "b": 2, "a": 3}`,
+			exp:   []string{`"a"`, `"b"`, `"a"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := New(l)
+
+			program := p.ParseProgram()
+			checkParseErrors(t, p)
+
+			stmt := program.Statements[0].(*ast.ExpressionStatement)
+			hash, ok := stmt.Expression.(*ast.HashLiteral)
+			if !ok {
+				t.Fatalf("expStmt not *ast.HashLiteral. got=%T", stmt.Expression)
+			}
+
+			keys := []string{}
+			for _, pair := range hash.Pairs {
+				keys = append(keys, pair.Key.String())
+			}
+			if !reflect.DeepEqual(keys, tt.exp) {
+				t.Errorf("want %q, but %q", tt.exp, keys)
+			}
+		})
+	}
+}
+
 func TestParsingEmptyHashLiteral(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -1290,15 +1339,16 @@ func TestParsingEmptyHashLiteralWithExpressions(t *testing.T) {
 				t.Fatalf("len(hash.Pairs) not 3. got=%d", len(hash.Pairs))
 			}
 
-			for k, v := range hash.Pairs {
+			for _, pair := range hash.Pairs {
+				k, v := pair.Key, pair.Value
 				literal, ok := k.(*ast.StringLiteral)
 				if !ok {
 					t.Errorf("k is not ast.StringLiteral. got=%T", k)
 				}
 
-				testFunc, ok := tt.testFuncs[literal.String()]
+				testFunc, ok := tt.testFuncs[literal.Value]
 				if !ok {
-					t.Errorf("No test function for key %q found", literal.String())
+					t.Errorf("No test function for key %q found", literal.Value)
 				}
 				testFunc(v)
 			}
@@ -1356,7 +1406,7 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 		{
 			name:   "`3 + 4; -5 * 5`",
 			fields: fields{input: `3 + 4; -5 * 5`},
-			exp:    exp{val: "(3 + 4)((-5) * 5)"},
+			exp:    exp{val: "(3 + 4); ((-5) * 5)"},
 		},
 		{
 			name:   "`5 > 4 == 3 < 4`",
@@ -1563,3 +1613,182 @@ func checkParseErrors(t *testing.T, p *Parser) {
 	}
 	t.FailNow()
 }
+
+func TestTryExpressionParsing(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		param      string
+		hasCatch   bool
+		hasFinally bool
+	}{
+		{"catch", `try { x } catch (e) { e }`, "e", true, false},
+		{"finally", `try { x } finally { y }`, "", false, true},
+		{"catch finally", `try { x } catch (err) { err } finally { y }`, "err", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := lexer.New(tt.input)
+			p := New(l)
+
+			program := p.ParseProgram()
+			checkParseErrors(t, p)
+
+			if len(program.Statements) != 1 {
+				t.Fatalf("Program.Statements does not contain %d statements. got = %d", 1, len(program.Statements))
+			}
+
+			expStmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+			if !ok {
+				t.Fatalf("expStmt not *ast.ExpressionStatement. got=%T", program.Statements[0])
+			}
+
+			te, ok := expStmt.Expression.(*ast.TryExpression)
+			if !ok {
+				t.Fatalf("expStmt not *ast.TryExpression. got=%T", expStmt.Expression)
+			}
+
+			if len(te.Block.Statements) != 1 {
+				t.Errorf("Block does not contain %d statements. got = %d", 1, len(te.Block.Statements))
+			}
+
+			if (te.Catch != nil) != tt.hasCatch {
+				t.Errorf("te.Catch is %#v", te.Catch)
+			}
+
+			if tt.hasCatch && te.Param.Value != tt.param {
+				t.Errorf("te.Param is not %q. got=%q", tt.param, te.Param.Value)
+			}
+
+			if (te.Finally != nil) != tt.hasFinally {
+				t.Errorf("te.Finally is %#v", te.Finally)
+			}
+		})
+	}
+}
+
+func TestTryExpressionParsingErrors(t *testing.T) {
+	l := lexer.New(`try { x }`)
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) != 1 {
+		t.Fatalf("parser has %d errors, want 1. %q", len(errors), errors)
+	}
+
+	exp := "expected catch or finally after try block, got EOF instead"
+	if errors[0] != exp {
+		t.Errorf("want %q, but %q", exp, errors[0])
+	}
+}
+
+func TestThrowStatement(t *testing.T) {
+	l := lexer.New(`throw "boom"; throw x + 1`)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParseErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("Program.Statements does not contain %d statements. got = %d", 2, len(program.Statements))
+	}
+
+	for i, exp := range []string{`throw "boom";`, `throw (x + 1);`} {
+		stmt, ok := program.Statements[i].(*ast.ThrowStatement)
+		if !ok {
+			t.Fatalf("stmt not *ast.ThrowStatement. got=%T", program.Statements[i])
+		}
+		if stmt.String() != exp {
+			t.Errorf("want %q, but %q", exp, stmt.String())
+		}
+	}
+}
+
+func TestYieldStatement(t *testing.T) {
+	l := lexer.New(`fn() { yield 1; let f = fn(x) { x }; yield* f(2); }; fn() { fn() { yield 1; } }`)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParseErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("Program.Statements does not contain %d statements. got = %d", 2, len(program.Statements))
+	}
+
+	gen := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if !gen.Generator {
+		t.Errorf("function with yield is not a generator")
+	}
+
+	stmt, ok := gen.Body.Statements[0].(*ast.YieldStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.YieldStatement. got=%T", gen.Body.Statements[0])
+	}
+	if stmt.String() != "yield 1;" {
+		t.Errorf("want %q, but %q", "yield 1;", stmt.String())
+	}
+
+	if s := gen.Body.Statements[2].String(); s != "yield* f(2);" {
+		t.Errorf("want %q, but %q", "yield* f(2);", s)
+	}
+
+	inner := gen.Body.Statements[1].(*ast.LetStatement).Value.(*ast.FunctionLiteral)
+	if inner.Generator {
+		t.Errorf("function without yield is a generator")
+	}
+
+	// only the function the yield appears in is a generator
+	outer := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	nested := outer.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if outer.Generator || !nested.Generator {
+		t.Errorf("wrong generators: outer=%t, nested=%t", outer.Generator, nested.Generator)
+	}
+}
+
+func TestYieldOutsideFunction(t *testing.T) {
+	p := New(lexer.New(`yield 1;`))
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) != 1 || errors[0] != "yield outside of function" {
+		t.Errorf("unexpected errors %q", errors)
+	}
+}
+
+func TestMacroLiteralParsing(t *testing.T) {
+	l := lexer.New(`macro(x, y) { x + y; }`)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParseErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("Program.Statements does not contain %d statements. got = %d", 1, len(program.Statements))
+	}
+
+	macro, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.MacroLiteral)
+	if !ok {
+		t.Fatalf("expression not *ast.MacroLiteral. got=%T", program.Statements[0].(*ast.ExpressionStatement).Expression)
+	}
+
+	if len(macro.Parameters) != 2 {
+		t.Fatalf("Parameters does not contain %d. got = %d", 2, len(macro.Parameters))
+	}
+	testLiteralExpression(t, macro.Parameters[0], "x")
+	testLiteralExpression(t, macro.Parameters[1], "y")
+
+	if len(macro.Body.Statements) != 1 {
+		t.Fatalf("macro.Body.Statements does not contain %d. got = %d", 1, len(macro.Body.Statements))
+	}
+	bodyStmt, ok := macro.Body.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("macro body stmt is not ast.ExpressionStatement. got=%T", macro.Body.Statements[0])
+	}
+	testInfixExpression(t, bodyStmt.Expression, "x", "+", "y")
+
+	if s := macro.String(); s != "macro(x, y) { (x + y) }" {
+		t.Errorf("want %q, but %q", "macro(x, y) { (x + y) }", s)
+	}
+}
@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"fmt"
+	goast "go/ast"
+	goparser "go/parser"
+	gotoken "go/token"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/smith-30/go-monkey/ast"
+	"github.com/smith-30/go-monkey/lexer"
+)
+
+// TestStringRoundTrip checks that the String of every program parsed by
+// the tests of this package parses back to the same tree.
+func TestStringRoundTrip(t *testing.T) {
+	inputs := append(corpus(t, "parser_test.go"),
+		`if (x) { x } else { y }; if (f(x)) { 1 }; if (!x) { 1 }; if (a[0]) { 1 }`,
+		`fn() {}; fn(x) { x; y }(1); if (a) { f } else { g }(2)`,
+		`try { f() } catch (e) { g(e) } finally { h() }; try { 1 } finally { 2 }`,
+		`{"one": 1, "two": [2, "two"], true: fn(x) { x }}["one"]`,
+		`a; (b); -c; [d]; e`,
+		`let g = fn() { yield 1; yield* [2]; return "done"; throw "never"; }`,
+		`let m = macro(x) { quote(unquote(x) + 1) }; m(2)`,
+	)
+
+	for _, input := range inputs {
+		p := New(lexer.New(input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			// tests of parse errors
+			continue
+		}
+
+		source := program.String()
+		p = New(lexer.New(source))
+		again := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Errorf("String of %q does not parse: %q\n%v", input, source, p.Errors())
+			continue
+		}
+
+		if want, got := dump(program), dump(again); !reflect.DeepEqual(want, got) {
+			t.Errorf("String of %q parses to another tree: %q\nwant %v\ngot  %v", input, source, want, got)
+		}
+	}
+}
+
+// corpus returns the Monkey sources in the Go test file name, which are
+// the arguments of lexer.New and the values of input fields.
+func corpus(t *testing.T, name string) []string {
+	t.Helper()
+
+	file, err := goparser.ParseFile(gotoken.NewFileSet(), name, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var inputs []string
+	add := func(e goast.Expr) {
+		lit, ok := e.(*goast.BasicLit)
+		if !ok || lit.Kind != gotoken.STRING {
+			return
+		}
+		s, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, s)
+	}
+
+	goast.Inspect(file, func(n goast.Node) bool {
+		switch n := n.(type) {
+		case *goast.CallExpr:
+			if sel, ok := n.Fun.(*goast.SelectorExpr); ok && sel.Sel.Name == "New" && len(n.Args) == 1 {
+				add(n.Args[0])
+			}
+		case *goast.KeyValueExpr:
+			if key, ok := n.Key.(*goast.Ident); ok && key.Name == "input" {
+				add(n.Value)
+			}
+		}
+		return true
+	})
+
+	if len(inputs) == 0 {
+		t.Fatalf("no inputs found in %s", name)
+	}
+	return inputs
+}
+
+// dump describes the structure of node, leaving out positions.
+func dump(node ast.Node) []string {
+	var out []string
+	ast.Inspect(node, func(n ast.Node) bool {
+		var s string
+		switch n := n.(type) {
+		case nil:
+			s = "end"
+		case *ast.Identifier:
+			s = "ident " + n.Value
+		case *ast.IntegerLiteral:
+			s = fmt.Sprintf("int %d", n.Value)
+		case *ast.StringLiteral:
+			s = fmt.Sprintf("string %q", n.Value)
+		case *ast.Boolean:
+			s = fmt.Sprintf("bool %t", n.Value)
+		case *ast.PrefixExpression:
+			s = "prefix " + n.Operator
+		case *ast.InfixExpression:
+			s = "infix " + n.Operator
+		case *ast.YieldStatement:
+			s = fmt.Sprintf("yield delegate=%t", n.Delegate)
+		case *ast.FunctionLiteral:
+			s = fmt.Sprintf("fn name=%q generator=%t params=%d", n.Name, n.Generator, len(n.Parameters))
+		case *ast.MacroLiteral:
+			s = fmt.Sprintf("macro params=%d", len(n.Parameters))
+		case *ast.IfExpression:
+			s = fmt.Sprintf("if else=%t", n.Alternative != nil)
+		case *ast.TryExpression:
+			s = fmt.Sprintf("try catch=%t finally=%t", n.Catch != nil, n.Finally != nil)
+		default:
+			s = fmt.Sprintf("%T", n)
+		}
+		out = append(out, s)
+		return true
+	})
+	return out
+}
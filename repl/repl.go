@@ -2,13 +2,11 @@ package repl
 
 import (
 	"bufio"
-	"fmt"
+	"context"
 	"io"
 
 	"github.com/smith-30/go-monkey/evaluator"
-	"github.com/smith-30/go-monkey/lexer"
 	"github.com/smith-30/go-monkey/object"
-	"github.com/smith-30/go-monkey/parser"
 )
 
 const (
@@ -31,31 +29,32 @@ const (
 
 func Start(in io.Reader, out io.Writer) {
 	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
+	interp := evaluator.NewInterpreter()
+	interp.Stdout = out
+	interp.Stderr = out
 
 	for {
-		fmt.Printf(PROMPT)
+		io.WriteString(out, PROMPT)
 		scanned := scanner.Scan()
 		if !scanned {
 			return
 		}
 
-		line := scanner.Text()
-		l := lexer.New(line)
-		p := parser.New(l)
-
-		program := p.ParseProgram()
-		if len(p.Errors()) != 0 {
-			printParseErrors(out, p.Errors())
+		evaluated, err := interp.Run(context.Background(), scanner.Text())
+		switch err := err.(type) {
+		case nil:
+		case *evaluator.ParseError:
+			printParseErrors(out, err.Errors)
+			continue
+		default:
+			io.WriteString(interp.Stderr, "ERROR: "+err.Error()+"\n")
 			continue
 		}
 
-		evaluated := evaluator.Eval(program, env)
 		if evaluated != nil {
-			io.WriteString(out, evaluated.Inspect())
+			io.WriteString(out, object.Repr(evaluated))
 			io.WriteString(out, "\n")
 		}
-
 	}
 }
 
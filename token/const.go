@@ -3,6 +3,7 @@ package token
 const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
+	COMMENT = "COMMENT" // only found in lexer.Comment
 
 	// 識別子 + リテラル
 	IDENT  = "IDENT" // add, foobar, x, y, ...
@@ -45,4 +46,10 @@ const (
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	TRY      = "TRY"
+	CATCH    = "CATCH"
+	FINALLY  = "FINALLY"
+	THROW    = "THROW"
+	YIELD    = "YIELD"
+	MACRO    = "MACRO"
 )
@@ -2,13 +2,19 @@ package token
 
 var (
 	keywords = map[string]TokenType{
-		"fn":  FUNCTION,
-		"let": LET,
-		"true":  TRUE,
-		"false": FALSE,
-		"if":  IF,
-		"else": ELSE,
+		"fn":      FUNCTION,
+		"let":     LET,
+		"true":    TRUE,
+		"false":   FALSE,
+		"if":      IF,
+		"else":    ELSE,
 		"return":  RETURN,
+		"try":     TRY,
+		"catch":   CATCH,
+		"finally": FINALLY,
+		"throw":   THROW,
+		"yield":   YIELD,
+		"macro":   MACRO,
 	}
 )
 
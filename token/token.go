@@ -1,10 +1,31 @@
 package token
 
+import "fmt"
+
 type TokenType string
 
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position
+}
+
+// Position is a location in source code. Line and Column start at 1.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// IsValid reports whether the position was set by the lexer.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
 }
 
 func NewToken(tt TokenType, ch byte) Token {